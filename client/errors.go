@@ -0,0 +1,93 @@
+package client
+
+import (
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/errors"
+)
+
+// Reason returns why perms-srv refused a request, one of the permsrv.Reason
+// constants, or "" if err isn't a refusal (nil, a network problem, or
+// perms-srv failing on its own).
+func Reason(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	e, ok := err.(*errors.Error)
+	if !ok {
+		e = errors.Parse(err.Error())
+	}
+
+	return e.Id
+}
+
+// Detail returns the human readable message of an error from perms-srv,
+// without the go-micro envelope.
+func Detail(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	e, ok := err.(*errors.Error)
+	if !ok {
+		e = errors.Parse(err.Error())
+	}
+
+	return e.Detail
+}
+
+// IsGroupNotFound reports whether the request named a group that doesn't
+// exist.
+func IsGroupNotFound(err error) bool {
+	return Reason(err) == permsrv.ReasonGroupNotFound
+}
+
+// IsGroupExists reports whether a group couldn't be created (or renamed)
+// because the name is taken.
+func IsGroupExists(err error) bool {
+	return Reason(err) == permsrv.ReasonGroupExists
+}
+
+// IsGroupNotEmpty reports whether a group couldn't be removed because it
+// still has members.
+func IsGroupNotEmpty(err error) bool {
+	return Reason(err) == permsrv.ReasonGroupNotEmpty
+}
+
+// IsAlreadyMember reports whether the user was already in the group.
+func IsAlreadyMember(err error) bool {
+	return Reason(err) == permsrv.ReasonAlreadyMember
+}
+
+// IsNotMember reports whether the user wasn't in the group.
+func IsNotMember(err error) bool {
+	return Reason(err) == permsrv.ReasonNotMember
+}
+
+// IsProtected reports whether the request tried to change server_admins.
+func IsProtected(err error) bool {
+	return Reason(err) == permsrv.ReasonProtectedGroup
+}
+
+// IsInvalid reports whether perms-srv refused the request as malformed: a
+// bad group name, a user it can't parse or missing fields.
+func IsInvalid(err error) bool {
+	switch Reason(err) {
+	case permsrv.ReasonInvalidRequest, permsrv.ReasonInvalidName, permsrv.ReasonInvalidUser:
+		return true
+	}
+
+	return false
+}
+
+// IsRevisionMismatch reports whether the group wasn't at the revision the
+// request expected, because someone else changed it in the meantime.
+func IsRevisionMismatch(err error) bool {
+	return Reason(err) == permsrv.ReasonRevisionMismatch
+}
+
+// IsChanged reports whether the groups changed while perms-srv was applying
+// the request. Retrying usually works.
+func IsChanged(err error) bool {
+	return Reason(err) == permsrv.ReasonChanged
+}
@@ -0,0 +1,1606 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/metadata"
+)
+
+// Call is one request made against a Fake.
+type Call struct {
+	Method  string
+	Request interface{}
+}
+
+// Fake is an in-memory permsrv.PermissionsService for testing commands without
+// perms-srv or the network. It follows perms-srv's rules: server_admins can do
+// anything and can't be changed through the API, groups must exist before
+// users are added and must be empty to be removed, and grants follow linked
+// identities. Every call is recorded, and errors can be injected per method.
+type Fake struct {
+	mu         sync.Mutex
+	groups     map[string]*permsrv.Permission
+	members    map[string]map[string]bool
+	identities map[string]string
+	principals map[string]map[string]bool
+	owners     map[string]map[string]bool
+	services   map[string]map[string]bool
+	registered map[string]bool
+	failures   map[string]error
+	calls      []Call
+	audit      []*permsrv.AuditEntry
+	nextId     int
+	revision   int64
+}
+
+var _ permsrv.PermissionsService = (*Fake)(nil)
+
+// NewFake returns a Fake with an empty server_admins group and the given
+// users as admins.
+func NewFake(admins ...string) *Fake {
+	f := &Fake{
+		groups:     make(map[string]*permsrv.Permission),
+		members:    make(map[string]map[string]bool),
+		identities: make(map[string]string),
+		principals: make(map[string]map[string]bool),
+		owners:     make(map[string]map[string]bool),
+		services:   make(map[string]map[string]bool),
+		registered: make(map[string]bool),
+		failures:   make(map[string]error),
+	}
+
+	f.SetGroup("server_admins", "Server Admins", admins...)
+	return f
+}
+
+// SetGroup creates or replaces a group and its members, bypassing the rules
+// the API enforces. It's meant for setting up test state.
+func (f *Fake) SetGroup(name, description string, users ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now().Unix()
+	f.groups[name] = &permsrv.Permission{Name: name, Description: description, Created: now, Updated: now}
+	f.members[name] = make(map[string]bool)
+	for user := range users {
+		if identity, err := fakeIdentity(users[user]); err == nil {
+			f.members[name][f.principalOf(identity)] = true
+		}
+	}
+}
+
+// FailWith makes every following call to method return err. A nil err stops
+// the injection again.
+func (f *Fake) FailWith(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.failures, method)
+		return
+	}
+
+	f.failures[method] = err
+}
+
+// Calls returns the calls made so far, optionally only those to method.
+func (f *Fake) Calls(method ...string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []Call
+	for call := range f.calls {
+		if len(method) == 0 || f.calls[call].Method == method[0] {
+			calls = append(calls, f.calls[call])
+		}
+	}
+
+	return calls
+}
+
+// Reset forgets the recorded calls.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = nil
+}
+
+// record adds an audit entry the way perms-srv would, made by the user named
+// in the X-Perms-User metadata. Callers must hold f.mu.
+func (f *Fake) record(ctx context.Context, action string, details ...string) {
+	md, _ := metadata.FromContext(ctx)
+	actor := md["X-Perms-User"]
+	if identity, err := fakeIdentity(actor); err == nil {
+		actor = identity
+	}
+
+	f.audit = append([]*permsrv.AuditEntry{{Time: time.Now().Unix(), Action: action, Details: details, Actor: actor}}, f.audit...)
+}
+
+// call records the request and returns the injected error, if any. Callers
+// must hold f.mu.
+func (f *Fake) call(method string, request interface{}) error {
+	f.calls = append(f.calls, Call{Method: method, Request: request})
+	return f.failures[method]
+}
+
+var fakeMention = regexp.MustCompile(`^<@!?(\d+)>$`)
+
+func fakeIdentity(user string) (string, error) {
+	if match := fakeMention.FindStringSubmatch(user); match != nil {
+		return PlatformDiscord + ":" + match[1], nil
+	}
+
+	platform, id := PlatformDiscord, user
+	if i := strings.Index(user, ":"); i >= 0 {
+		platform, id = strings.ToLower(user[:i]), user[i+1:]
+	}
+
+	if platform == "" {
+		platform = PlatformDiscord
+	}
+
+	switch {
+	case id == "":
+		return "", errors.BadRequest(permsrv.ReasonInvalidUser, "No user given.")
+	case platform != PlatformDiscord && platform != PlatformSlack && platform != PlatformEve:
+		return "", errors.BadRequest(permsrv.ReasonInvalidUser, "Unknown platform `%s`.", platform)
+	}
+
+	return platform + ":" + id, nil
+}
+
+var fakeValidName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+func fakeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func fakeCheckName(name string) (string, error) {
+	canonical := fakeName(name)
+
+	reason := ""
+	switch {
+	case canonical == "":
+		reason = "the name is empty"
+	case len(canonical) > 64:
+		reason = "names can't be longer than 64 characters"
+	case canonical == "server_admins":
+		reason = "the name is reserved"
+	case !fakeValidName.MatchString(canonical):
+		reason = "names may only contain letters, digits, `_`, `-` and `.` and must start with a letter or digit"
+	}
+
+	if reason != "" {
+		return "", errors.BadRequest(permsrv.ReasonInvalidName, "Invalid permission group name `%s`: %s.", name, reason)
+	}
+
+	return canonical, nil
+}
+
+func (f *Fake) principalOf(identity string) string {
+	if principal, ok := f.identities[identity]; ok {
+		return principal
+	}
+
+	return identity
+}
+
+func (f *Fake) subjects(user string) ([]string, error) {
+	identity, err := fakeIdentity(user)
+	if err != nil {
+		return nil, err
+	}
+
+	principal := f.principalOf(identity)
+	subjects := []string{principal, identity}
+	for i := range f.principals[principal] {
+		subjects = append(subjects, i)
+	}
+
+	return subjects, nil
+}
+
+func (f *Fake) isMemberAny(group string, subjects []string) bool {
+	for subject := range subjects {
+		if f.members[group][subjects[subject]] {
+			return true
+		}
+	}
+
+	return false
+}
+
+var errFakeAdminIdentity = errors.Forbidden(permsrv.ReasonProtectedGroup, "The identities of server_admins members can't be linked or unlinked through the API, only `perms-srv import -admins` can change them.")
+
+// touchesAdmin reports whether any of the identities, or anyone linked to
+// them, is an admin, whose links perms-srv won't change. Callers must hold
+// f.mu.
+func (f *Fake) touchesAdmin(identities ...string) bool {
+	for i := range identities {
+		if subjects, err := f.subjects(identities[i]); err == nil && f.isMemberAny("server_admins", subjects) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Fake) principal(principal string) *permsrv.Principal {
+	response := &permsrv.Principal{Id: principal}
+	for _, identity := range sortedKeys(f.principals[principal]) {
+		i := strings.Index(identity, ":")
+		response.Identities = append(response.Identities, &permsrv.Identity{Platform: identity[:i], Id: identity[i+1:]})
+	}
+
+	return response
+}
+
+// fakeUser renders an identity the way perms-srv shows users: Discord ids
+// bare, anything else as platform:id.
+func fakeUser(identity string) string {
+	return strings.TrimPrefix(identity, PlatformDiscord+":")
+}
+
+// memberNames shows members by their identity on platform, or their first one
+// if they have none there, the way perms-srv does. Callers must hold f.mu.
+func (f *Fake) memberNames(members []string, platform string) []string {
+	if platform = strings.ToLower(platform); platform == "" {
+		platform = PlatformDiscord
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, member := range members {
+		identities := sortedKeys(f.principals[member])
+		if len(identities) == 0 {
+			identities = []string{member}
+		}
+
+		chosen := identities[0]
+		for _, identity := range identities {
+			if strings.HasPrefix(identity, platform+":") {
+				chosen = identity
+				break
+			}
+		}
+
+		if name := fakeUser(chosen); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// group returns a copy of the named group, or nil. Callers must hold f.mu.
+func (f *Fake) group(name string) *permsrv.Permission {
+	group, ok := f.groups[name]
+	if !ok {
+		return nil
+	}
+
+	perm := *group
+	perm.System = perm.System || name == "server_admins"
+	return &perm
+}
+
+// touch bumps the modification time and revision of a group. Callers must
+// hold f.mu.
+func (f *Fake) touch(name string) {
+	if group, ok := f.groups[name]; ok {
+		group.Updated = time.Now().Unix()
+		group.Revision++
+	}
+
+	f.revision++
+}
+
+// checkRevision fails like perms-srv does when the group isn't at the
+// expected revision. Callers must hold f.mu.
+func (f *Fake) checkRevision(name string, expected int64) error {
+	if expected == 0 || f.groups[name].Revision == expected {
+		return nil
+	}
+
+	return errors.Conflict(permsrv.ReasonRevisionMismatch,
+		"Permission group `%s` is at revision %d, not %d. Someone else changed it, please reload and try again.",
+		name, f.groups[name].Revision, expected)
+}
+
+func (f *Fake) Perform(ctx context.Context, in *permsrv.PermissionsRequest, opts ...client.CallOption) (*permsrv.PerformResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("Perform", in); err != nil {
+		return nil, err
+	}
+
+	return &permsrv.PerformResponse{CanPerform: f.canPerform(in)}, nil
+}
+
+func (f *Fake) PerformBatch(ctx context.Context, in *permsrv.PerformBatchRequest, opts ...client.CallOption) (*permsrv.PerformBatchResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("PerformBatch", in); err != nil {
+		return nil, err
+	}
+
+	if len(in.Requests) > 1000 {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "A batch can't hold more than 1000 requests.")
+	}
+
+	response := &permsrv.PerformBatchResponse{}
+	for request := range in.Requests {
+		response.Responses = append(response.Responses, &permsrv.PerformResponse{CanPerform: f.canPerform(in.Requests[request])})
+	}
+
+	return response, nil
+}
+
+// canPerform denies users that aren't valid identities, like perms-srv.
+func (f *Fake) canPerform(in *permsrv.PermissionsRequest) bool {
+	subjects, err := f.subjects(in.User)
+	if err != nil {
+		return false
+	}
+
+	if f.isMemberAny("server_admins", subjects) {
+		return true
+	}
+
+	for perm := range in.PermissionsList {
+		if f.isMemberAny(fakeName(in.PermissionsList[perm]), subjects) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Fake) AddPermission(ctx context.Context, in *permsrv.Permission, opts ...client.CallOption) (*permsrv.Permission, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("AddPermission", in); err != nil {
+		return nil, err
+	}
+
+	if fakeName(in.Name) == "server_admins" {
+		return nil, errors.Forbidden(permsrv.ReasonProtectedGroup, "You cannot add the server_admins group.")
+	}
+
+	name, err := fakeCheckName(in.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := f.groups[name]; ok {
+		return nil, errors.Conflict(permsrv.ReasonGroupExists, "Permission group `%s` already exists.", name)
+	}
+
+	now := time.Now().Unix()
+	f.groups[name] = &permsrv.Permission{
+		Name:        name,
+		Description: in.Description,
+		Owner:       in.Owner,
+		Creator:     in.Creator,
+		Created:     now,
+		Updated:     now,
+		Tags:        in.Tags,
+		System:      in.System,
+		Revision:    1,
+	}
+	f.revision++
+	f.members[name] = make(map[string]bool)
+	f.record(ctx, "AddPermission", name)
+	return f.group(name), nil
+}
+
+func (f *Fake) UpdatePermission(ctx context.Context, in *permsrv.Permission, opts ...client.CallOption) (*permsrv.Permission, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("UpdatePermission", in); err != nil {
+		return nil, err
+	}
+
+	name := fakeName(in.Name)
+
+	if name == "server_admins" {
+		return nil, errors.Forbidden(permsrv.ReasonProtectedGroup, "You cannot change the server_admins group.")
+	}
+
+	if _, ok := f.groups[name]; !ok {
+		return nil, errors.NotFound(permsrv.ReasonGroupNotFound, "Permission group `%s` doesn't exists.", name)
+	}
+
+	if err := f.checkRevision(name, in.ExpectedRevision); err != nil {
+		return nil, err
+	}
+
+	group := f.groups[name]
+	if in.Description != "" {
+		group.Description = in.Description
+	}
+
+	if in.Owner != "" {
+		group.Owner = in.Owner
+	}
+
+	if in.Tags != nil {
+		group.Tags = in.Tags
+	}
+
+	f.touch(name)
+	f.record(ctx, "UpdatePermission", name)
+	return f.group(name), nil
+}
+
+func (f *Fake) RenamePermission(ctx context.Context, in *permsrv.RenamePermissionRequest, opts ...client.CallOption) (*permsrv.Permission, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("RenamePermission", in); err != nil {
+		return nil, err
+	}
+
+	name := fakeName(in.Name)
+
+	switch {
+	case name == "server_admins" || fakeName(in.NewName) == "server_admins":
+		return nil, errors.Forbidden(permsrv.ReasonProtectedGroup, "You cannot rename the server_admins group.")
+	case in.NewName == "":
+		return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "Renaming a permission group needs a new name.")
+	}
+
+	newName, err := fakeCheckName(in.NewName)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == newName {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidName, "Permission group `%s` already has that name.", name)
+	}
+
+	group, ok := f.groups[name]
+	if !ok {
+		return nil, errors.NotFound(permsrv.ReasonGroupNotFound, "Permission group `%s` doesn't exists.", name)
+	}
+
+	if err := f.checkRevision(name, in.ExpectedRevision); err != nil {
+		return nil, err
+	}
+
+	if _, taken := f.groups[newName]; taken {
+		return nil, errors.Conflict(permsrv.ReasonGroupExists, "Permission group `%s` already exists.", newName)
+	}
+
+	group.Name = newName
+	f.groups[newName], f.members[newName] = group, f.members[name]
+	delete(f.groups, name)
+	delete(f.members, name)
+
+	if owners, ok := f.owners[name]; ok {
+		f.owners[newName] = owners
+		delete(f.owners, name)
+
+		for service := range owners {
+			delete(f.services[service], name)
+			f.services[service][newName] = true
+		}
+	}
+
+	if f.registered[name] {
+		delete(f.registered, name)
+		f.registered[newName] = true
+	}
+
+	f.touch(newName)
+	f.record(ctx, "RenamePermission", name, newName)
+	return f.group(newName), nil
+}
+
+func (f *Fake) AddPermissionUser(ctx context.Context, in *permsrv.PermissionUser, opts ...client.CallOption) (*permsrv.PermissionUser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("AddPermissionUser", in); err != nil {
+		return nil, err
+	}
+
+	perm := fakeName(in.Permission)
+
+	if perm == "server_admins" {
+		return nil, errors.Forbidden(permsrv.ReasonProtectedGroup, "You cannot add users to the server_admins group.")
+	}
+
+	if _, ok := f.groups[perm]; !ok {
+		return nil, errors.NotFound(permsrv.ReasonGroupNotFound, "Permission group `%s` doesn't exists.", perm)
+	}
+
+	subjects, err := f.subjects(in.User)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.checkRevision(perm, in.ExpectedRevision); err != nil {
+		return nil, err
+	}
+
+	if f.isMemberAny(perm, subjects) {
+		return nil, errors.Conflict(permsrv.ReasonAlreadyMember, "`%s` is already a member of group '%s'", in.User, perm)
+	}
+
+	f.members[perm][subjects[0]] = true
+	f.touch(perm)
+	f.record(ctx, "AddPermissionUser", perm+" "+subjects[0])
+	return &permsrv.PermissionUser{User: fakeUser(subjects[1]), Permission: perm, Group: f.group(perm)}, nil
+}
+
+func (f *Fake) RemovePermission(ctx context.Context, in *permsrv.Permission, opts ...client.CallOption) (*permsrv.Permission, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("RemovePermission", in); err != nil {
+		return nil, err
+	}
+
+	name := fakeName(in.Name)
+
+	if name == "server_admins" {
+		return nil, errors.Forbidden(permsrv.ReasonProtectedGroup, "You cannot delete the server_admins group.")
+	}
+
+	group := f.group(name)
+	if group == nil {
+		return nil, errors.NotFound(permsrv.ReasonGroupNotFound, "Permission group `%s` doesn't exists.", name)
+	}
+
+	if err := f.checkRevision(name, in.ExpectedRevision); err != nil {
+		return nil, err
+	}
+
+	if len(f.members[name]) > 0 {
+		return nil, errors.Conflict(permsrv.ReasonGroupNotEmpty, "Permission group `%s` not empty.", name)
+	}
+
+	f.revision++
+	delete(f.groups, name)
+	delete(f.members, name)
+	for service := range f.owners[name] {
+		delete(f.services[service], name)
+	}
+	delete(f.owners, name)
+	delete(f.registered, name)
+	f.record(ctx, "RemovePermission", name)
+
+	return group, nil
+}
+
+func (f *Fake) RemovePermissionUser(ctx context.Context, in *permsrv.PermissionUser, opts ...client.CallOption) (*permsrv.PermissionUser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("RemovePermissionUser", in); err != nil {
+		return nil, err
+	}
+
+	perm := fakeName(in.Permission)
+
+	if perm == "server_admins" {
+		return nil, errors.Forbidden(permsrv.ReasonProtectedGroup, "You cannot remove users from the server_admins group.")
+	}
+
+	if _, ok := f.groups[perm]; !ok {
+		return nil, errors.NotFound(permsrv.ReasonGroupNotFound, "Permission group `%s` doesn't exists.", perm)
+	}
+
+	subjects, err := f.subjects(in.User)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.checkRevision(perm, in.ExpectedRevision); err != nil {
+		return nil, err
+	}
+
+	if !f.isMemberAny(perm, subjects) {
+		return nil, errors.NotFound(permsrv.ReasonNotMember, "`%s` not a member of group '%s'", in.User, perm)
+	}
+
+	for subject := range subjects {
+		delete(f.members[perm], subjects[subject])
+	}
+	f.touch(perm)
+	f.record(ctx, "RemovePermissionUser", perm+" "+subjects[0])
+
+	return &permsrv.PermissionUser{User: fakeUser(subjects[1]), Permission: perm, Group: f.group(perm)}, nil
+}
+
+func (f *Fake) ListPermissions(ctx context.Context, in *permsrv.NilRequest, opts ...client.CallOption) (*permsrv.PermissionsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("ListPermissions", in); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range f.groups {
+		names = append(names, name)
+	}
+
+	return f.permissionsPage(names, nil)
+}
+
+func (f *Fake) ListPermissionsPage(ctx context.Context, in *permsrv.ListPermissionsRequest, opts ...client.CallOption) (*permsrv.PermissionsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("ListPermissionsPage", in); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range f.groups {
+		names = append(names, name)
+	}
+
+	return f.permissionsPage(names, in.Page)
+}
+
+func (f *Fake) GetPermission(ctx context.Context, in *permsrv.Permission, opts ...client.CallOption) (*permsrv.Permission, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("GetPermission", in); err != nil {
+		return nil, err
+	}
+
+	name := fakeName(in.Name)
+
+	group := f.group(name)
+	if group == nil {
+		return nil, errors.NotFound(permsrv.ReasonGroupNotFound, "Permission group `%s` doesn't exists.", name)
+	}
+
+	return group, nil
+}
+
+func (f *Fake) ListPermissionUsers(ctx context.Context, in *permsrv.UsersRequest, opts ...client.CallOption) (*permsrv.UsersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("ListPermissionUsers", in); err != nil {
+		return nil, err
+	}
+
+	perm := fakeName(in.Permission)
+
+	users, next, err := fakePage(f.memberNames(sortedKeys(f.members[perm]), in.Platform), in.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &permsrv.UsersResponse{UserList: users, NextPageToken: next}
+	if group, ok := f.groups[perm]; ok {
+		response.Revision = group.Revision
+	}
+
+	return response, nil
+}
+
+func (f *Fake) ListUserPermissions(ctx context.Context, in *permsrv.PermissionUser, opts ...client.CallOption) (*permsrv.PermissionsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("ListUserPermissions", in); err != nil {
+		return nil, err
+	}
+
+	return f.userPermissions(in.User, nil)
+}
+
+func (f *Fake) ListUserPermissionsPage(ctx context.Context, in *permsrv.UserPermissionsRequest, opts ...client.CallOption) (*permsrv.PermissionsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("ListUserPermissionsPage", in); err != nil {
+		return nil, err
+	}
+
+	return f.userPermissions(in.User, in.Page)
+}
+
+// userPermissions lists the groups user is in. Callers must hold f.mu.
+func (f *Fake) userPermissions(user string, page *permsrv.PageRequest) (*permsrv.PermissionsResponse, error) {
+	subjects, err := f.subjects(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range f.members {
+		if _, ok := f.groups[name]; ok && f.isMemberAny(name, subjects) {
+			names = append(names, name)
+		}
+	}
+
+	return f.permissionsPage(names, page)
+}
+
+func (f *Fake) permissionsPage(names []string, page *permsrv.PageRequest) (*permsrv.PermissionsResponse, error) {
+	names, next, err := fakePage(names, page)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &permsrv.PermissionsResponse{NextPageToken: next, Revision: f.revision}
+	for _, name := range names {
+		response.PermissionsList = append(response.PermissionsList, f.group(name))
+	}
+
+	return response, nil
+}
+
+// fakePage pages through names the same way perms-srv does.
+func fakePage(names []string, page *permsrv.PageRequest) ([]string, string, error) {
+	var selected []string
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if page == nil || strings.HasPrefix(lower, strings.ToLower(page.Prefix)) &&
+			strings.Contains(lower, strings.ToLower(page.Contains)) {
+			selected = append(selected, name)
+		}
+	}
+
+	sort.Strings(selected)
+
+	if page == nil {
+		return selected, "", nil
+	}
+
+	if page.Token != "" {
+		after, err := base64.RawURLEncoding.DecodeString(page.Token)
+		if err != nil {
+			return nil, "", errors.BadRequest(permsrv.ReasonInvalidRequest, "Invalid page token.")
+		}
+
+		start := sort.Search(len(selected), func(i int) bool { return selected[i] > string(after) })
+		selected = selected[start:]
+	}
+
+	size := int(page.Size)
+	switch {
+	case size <= 0:
+		size = 100
+	case size > 1000:
+		size = 1000
+	}
+
+	if len(selected) <= size {
+		return selected, "", nil
+	}
+
+	return selected[:size], base64.RawURLEncoding.EncodeToString([]byte(selected[size-1])), nil
+}
+
+func (f *Fake) LinkIdentity(ctx context.Context, in *permsrv.IdentityLink, opts ...client.CallOption) (*permsrv.Principal, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("LinkIdentity", in); err != nil {
+		return nil, err
+	}
+
+	if in.Existing == nil || in.Identity == nil {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidUser, "No user given.")
+	}
+
+	existing, err := fakeIdentity(in.Existing.Platform + ":" + in.Existing.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := fakeIdentity(in.Identity.Platform + ":" + in.Identity.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, ok := f.identities[existing]
+	if !ok {
+		f.nextId++
+		principal = fmt.Sprintf("principal-%d", f.nextId)
+	}
+
+	if current, ok := f.identities[identity]; ok && current != principal {
+		return nil, errors.Conflict(permsrv.ReasonIdentityLinked, "Identity `%s` is already linked to someone else.", identity)
+	}
+
+	if f.touchesAdmin(existing, identity) {
+		return nil, errFakeAdminIdentity
+	}
+
+	if f.principals[principal] == nil {
+		f.principals[principal] = make(map[string]bool)
+	}
+
+	f.identities[existing] = principal
+	f.identities[identity] = principal
+	f.principals[principal][existing] = true
+	f.principals[principal][identity] = true
+	f.record(ctx, "LinkIdentity", principal, existing, identity)
+
+	return f.principal(principal), nil
+}
+
+func (f *Fake) UnlinkIdentity(ctx context.Context, in *permsrv.Identity, opts ...client.CallOption) (*permsrv.Principal, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("UnlinkIdentity", in); err != nil {
+		return nil, err
+	}
+
+	identity, err := fakeIdentity(in.Platform + ":" + in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, ok := f.identities[identity]
+	if !ok {
+		return nil, errors.NotFound(permsrv.ReasonIdentityNotLinked, "Identity `%s` isn't linked to anyone.", identity)
+	}
+
+	if f.touchesAdmin(identity) {
+		return nil, errFakeAdminIdentity
+	}
+
+	delete(f.identities, identity)
+	delete(f.principals[principal], identity)
+	f.record(ctx, "UnlinkIdentity", principal, identity)
+
+	return f.principal(principal), nil
+}
+
+func (f *Fake) GetPrincipal(ctx context.Context, in *permsrv.Identity, opts ...client.CallOption) (*permsrv.Principal, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("GetPrincipal", in); err != nil {
+		return nil, err
+	}
+
+	identity, err := fakeIdentity(in.Platform + ":" + in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := f.principal(f.principalOf(identity))
+	if len(response.Identities) == 0 {
+		i := strings.Index(identity, ":")
+		response.Identities = []*permsrv.Identity{{Platform: identity[:i], Id: identity[i+1:]}}
+	}
+
+	return response, nil
+}
+
+func (f *Fake) RegisterPermissions(ctx context.Context, in *permsrv.PermissionsRegistration, opts ...client.CallOption) (*permsrv.RegistrationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("RegisterPermissions", in); err != nil {
+		return nil, err
+	}
+
+	if in.Service == "" {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "Registering permissions requires a service name.")
+	}
+
+	for _, perm := range in.PermissionsList {
+		if fakeName(perm.Name) == "server_admins" {
+			continue
+		}
+
+		if _, err := fakeCheckName(perm.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	owner := in.Service
+	if in.Command != "" {
+		owner += "/" + in.Command
+	}
+
+	response := &permsrv.RegistrationResponse{}
+	declared := make(map[string]bool)
+
+	for _, perm := range in.PermissionsList {
+		name := fakeName(perm.Name)
+		if name == "server_admins" || declared[name] {
+			continue
+		}
+
+		declared[name] = true
+
+		if _, ok := f.groups[name]; !ok {
+			now := time.Now().Unix()
+			f.groups[name] = &permsrv.Permission{
+				Name:        name,
+				Description: perm.Description,
+				Owner:       owner,
+				Creator:     owner,
+				Created:     now,
+				Updated:     now,
+				Tags:        perm.Tags,
+				System:      true,
+				Revision:    1,
+			}
+			f.revision++
+			f.members[name] = make(map[string]bool)
+			response.Created = append(response.Created, f.group(name))
+		}
+
+		if f.owners[name] == nil {
+			f.owners[name] = make(map[string]bool)
+		}
+
+		f.owners[name][owner] = true
+		f.registered[name] = true
+	}
+
+	for name := range f.services[owner] {
+		if !declared[name] {
+			delete(f.owners[name], owner)
+		}
+	}
+
+	f.services[owner] = declared
+	if len(response.Created) > 0 {
+		details := []string{owner}
+		for _, perm := range response.Created {
+			details = append(details, perm.Name)
+		}
+
+		f.record(ctx, "RegisterPermissions", details...)
+	}
+	response.Undeclared = f.undeclared()
+
+	return response, nil
+}
+
+func (f *Fake) ListUndeclaredPermissions(ctx context.Context, in *permsrv.NilRequest, opts ...client.CallOption) (*permsrv.PermissionsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("ListUndeclaredPermissions", in); err != nil {
+		return nil, err
+	}
+
+	return &permsrv.PermissionsResponse{PermissionsList: f.undeclared(), Revision: f.revision}, nil
+}
+
+func (f *Fake) undeclared() []*permsrv.Permission {
+	var undeclared []*permsrv.Permission
+	for _, name := range sortedKeys(f.registered) {
+		if _, ok := f.groups[name]; ok && len(f.owners[name]) == 0 {
+			undeclared = append(undeclared, f.group(name))
+		}
+	}
+
+	return undeclared
+}
+
+func (f *Fake) AddPermissionUsers(ctx context.Context, in *permsrv.BulkPermissionUsers, opts ...client.CallOption) (*permsrv.BulkPermissionUsersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("AddPermissionUsers", in); err != nil {
+		return nil, err
+	}
+
+	return f.bulkMembership(ctx, "AddPermissionUsers", in, true)
+}
+
+func (f *Fake) RemovePermissionUsers(ctx context.Context, in *permsrv.BulkPermissionUsers, opts ...client.CallOption) (*permsrv.BulkPermissionUsersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("RemovePermissionUsers", in); err != nil {
+		return nil, err
+	}
+
+	return f.bulkMembership(ctx, "RemovePermissionUsers", in, false)
+}
+
+func (f *Fake) bulkMembership(ctx context.Context, action string, in *permsrv.BulkPermissionUsers, add bool) (*permsrv.BulkPermissionUsersResponse, error) {
+	if len(in.Users) == 0 || len(in.Permissions) == 0 {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "Bulk changes need at least one user and one permission group.")
+	}
+
+	response := &permsrv.BulkPermissionUsersResponse{}
+	subjects := make(map[*permsrv.PermissionUserResult][]string)
+	failed := false
+
+	for _, perm := range in.Permissions {
+		perm = fakeName(perm)
+		for _, user := range in.Users {
+			result := &permsrv.PermissionUserResult{User: user, Permission: perm, Success: true}
+			s, err := f.subjects(user)
+			_, exists := f.groups[perm]
+
+			switch {
+			case err != nil:
+			case perm == "server_admins" && add:
+				err = errors.Forbidden(permsrv.ReasonProtectedGroup, "You cannot add users to the server_admins group.")
+			case perm == "server_admins":
+				err = errors.Forbidden(permsrv.ReasonProtectedGroup, "You cannot remove users from the server_admins group.")
+			case !exists:
+				err = errors.NotFound(permsrv.ReasonGroupNotFound, "Permission group `%s` doesn't exists.", perm)
+			case add && f.isMemberAny(perm, s):
+				err = errors.Conflict(permsrv.ReasonAlreadyMember, "`%s` is already a member of group '%s'", user, perm)
+			case !add && !f.isMemberAny(perm, s):
+				err = errors.NotFound(permsrv.ReasonNotMember, "`%s` not a member of group '%s'", user, perm)
+			}
+
+			if err != nil {
+				result.Success, result.Error, result.Reason, failed = false, Detail(err), Reason(err), true
+			}
+
+			subjects[result] = s
+			response.Results = append(response.Results, result)
+		}
+	}
+
+	var details []string
+	touched := make(map[string]bool)
+	for _, result := range response.Results {
+		switch {
+		case !result.Success:
+		case in.Atomic && failed:
+			result.Success, result.Error, result.Reason = false, "Not applied, another change in the request failed.", permsrv.ReasonNotApplied
+		case add:
+			f.members[result.Permission][subjects[result][0]] = true
+			touched[result.Permission] = true
+			details = append(details, result.Permission+" "+subjects[result][0])
+		default:
+			for _, subject := range subjects[result] {
+				delete(f.members[result.Permission], subject)
+			}
+			touched[result.Permission] = true
+			details = append(details, result.Permission+" "+subjects[result][0])
+		}
+	}
+
+	for name := range touched {
+		f.touch(name)
+	}
+
+	if len(details) > 0 {
+		f.record(ctx, action, details...)
+	}
+
+	return response, nil
+}
+
+func (f *Fake) ListAuditEntries(ctx context.Context, in *permsrv.AuditRequest, opts ...client.CallOption) (*permsrv.AuditResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("ListAuditEntries", in); err != nil {
+		return nil, err
+	}
+
+	limit := int(in.Limit)
+	if limit <= 0 {
+		limit = 100
+	}
+
+	if limit > len(f.audit) {
+		limit = len(f.audit)
+	}
+
+	return &permsrv.AuditResponse{Entries: f.audit[:limit]}, nil
+}
+
+func (f *Fake) SetPermissionUsers(ctx context.Context, in *permsrv.SetPermissionUsersRequest, opts ...client.CallOption) (*permsrv.SetPermissionUsersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("SetPermissionUsers", in); err != nil {
+		return nil, err
+	}
+
+	perm := fakeName(in.Permission)
+
+	if perm == "server_admins" {
+		return nil, errors.Forbidden(permsrv.ReasonProtectedGroup, "You cannot change the members of the server_admins group.")
+	}
+
+	if _, ok := f.groups[perm]; !ok {
+		return nil, errors.NotFound(permsrv.ReasonGroupNotFound, "Permission group `%s` doesn't exists.", perm)
+	}
+
+	if err := f.checkRevision(perm, in.ExpectedRevision); err != nil {
+		return nil, err
+	}
+
+	response := &permsrv.SetPermissionUsersResponse{}
+	wanted := make(map[string]bool)
+	added := make(map[string]bool)
+
+	for _, user := range in.Users {
+		subjects, err := f.subjects(user)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, subject := range subjects {
+			wanted[subject] = true
+		}
+
+		if !f.isMemberAny(perm, subjects) && !added[subjects[0]] {
+			added[subjects[0]] = true
+			response.Added = append(response.Added, subjects[0])
+		}
+	}
+
+	for _, member := range sortedKeys(f.members[perm]) {
+		if !wanted[member] {
+			response.Removed = append(response.Removed, member)
+		}
+	}
+
+	joining, leaving := response.Added, response.Removed
+	response.Added = f.memberNames(joining, "")
+	response.Removed = f.memberNames(leaving, "")
+	sort.Strings(response.Added)
+	sort.Strings(response.Removed)
+
+	if in.DryRun || len(joining)+len(leaving) == 0 {
+		return response, nil
+	}
+
+	var details []string
+	for _, member := range joining {
+		f.members[perm][member] = true
+		details = append(details, "+"+perm+" "+member)
+	}
+
+	for _, member := range leaving {
+		delete(f.members[perm], member)
+		details = append(details, "-"+perm+" "+member)
+	}
+
+	f.touch(perm)
+	f.record(ctx, "SetPermissionUsers", details...)
+	return response, nil
+}
+
+func (f *Fake) Export(ctx context.Context, in *permsrv.ExportRequest, opts ...client.CallOption) (*permsrv.ExportResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("Export", in); err != nil {
+		return nil, err
+	}
+
+	format, err := permsrv.DumpFormat(in.Format)
+	if err != nil {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "%s", err)
+	}
+
+	data, err := f.dump().Marshal(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &permsrv.ExportResponse{Data: data, Format: format}, nil
+}
+
+// dump returns the fake's state as perms-srv would export it. Callers must
+// hold f.mu.
+func (f *Fake) dump() *permsrv.Dump {
+	dump := &permsrv.Dump{Version: permsrv.DumpVersion, Exported: time.Now().Unix()}
+
+	var names []string
+	for name := range f.groups {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		perm := f.group(name)
+		dump.Groups = append(dump.Groups, &permsrv.DumpGroup{
+			Name:        name,
+			Description: perm.Description,
+			Owner:       perm.Owner,
+			Creator:     perm.Creator,
+			Created:     perm.Created,
+			Updated:     perm.Updated,
+			Tags:        perm.Tags,
+			System:      perm.System,
+			Members:     sortedKeys(f.members[name]),
+			Services:    sortedKeys(f.owners[name]),
+			Registered:  f.registered[name],
+		})
+	}
+
+	for identity, principal := range f.identities {
+		if dump.Identities == nil {
+			dump.Identities = make(map[string][]string)
+		}
+
+		dump.Identities[principal] = append(dump.Identities[principal], identity)
+	}
+
+	for principal := range dump.Identities {
+		sort.Strings(dump.Identities[principal])
+	}
+
+	return dump
+}
+
+func (f *Fake) Import(ctx context.Context, in *permsrv.ImportRequest, opts ...client.CallOption) (*permsrv.ImportResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("Import", in); err != nil {
+		return nil, err
+	}
+
+	mode := strings.ToLower(in.Mode)
+	if mode == "" {
+		mode = permsrv.ImportMerge
+	}
+
+	if mode != permsrv.ImportMerge && mode != permsrv.ImportReplace {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "Unknown import mode `%s`, use merge or replace.", in.Mode)
+	}
+
+	wanted, err := permsrv.UnmarshalDump(in.Data, in.Format)
+	if err != nil {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "%s", err)
+	}
+
+	if wanted.Version != permsrv.DumpVersion {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "Can't import dumps of version %d, only version %d.", wanted.Version, permsrv.DumpVersion)
+	}
+
+	seen := make(map[string]bool)
+	for _, group := range wanted.Groups {
+		if group == nil {
+			return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "The dump has an empty group.")
+		}
+
+		group.Name = fakeName(group.Name)
+		if group.Name != "server_admins" {
+			if _, err := fakeCheckName(group.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		if seen[group.Name] {
+			return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "Permission group `%s` is in the dump twice.", group.Name)
+		}
+
+		seen[group.Name] = true
+	}
+
+	links := make(map[string]string)
+	for principal, identities := range wanted.Identities {
+		for _, identity := range identities {
+			identity, err := fakeIdentity(identity)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := links[identity]; ok {
+				return nil, errors.BadRequest(permsrv.ReasonInvalidRequest, "Identity `%s` is linked to more than one principal.", identity)
+			}
+
+			links[identity] = principal
+		}
+	}
+
+	// Like perms-srv, only `perms-srv import -admins` changes the admins,
+	// their identities included.
+	admins := make(map[string]bool)
+	for member := range f.members["server_admins"] {
+		admins[member] = true
+	}
+
+	for _, group := range wanted.Groups {
+		if group.Name == "server_admins" {
+			for _, member := range group.Members {
+				admins[member] = true
+			}
+		}
+	}
+
+	for _, links := range []map[string]string{f.identities, links} {
+		for identity, principal := range links {
+			if admins[identity] {
+				admins[principal] = true
+			}
+		}
+	}
+
+	replace := mode == permsrv.ImportReplace
+	response := &permsrv.ImportResponse{}
+	var apply []func()
+	var protected []string
+	admin := false
+	change := func(do func(), format string, args ...interface{}) {
+		response.Changes = append(response.Changes, fmt.Sprintf(format, args...))
+		apply = append(apply, do)
+		if admin {
+			protected = append(protected, response.Changes[len(response.Changes)-1])
+		}
+	}
+
+	for _, group := range wanted.Groups {
+		group := group
+		name := group.Name
+		old := f.group(name)
+		start := len(apply)
+		admin = name == "server_admins"
+
+		switch {
+		case old == nil:
+			change(func() {
+				f.groups[name] = &permsrv.Permission{Name: name, Revision: 1}
+				f.members[name] = make(map[string]bool)
+				f.setGroup(group)
+			}, "+group %s", name)
+		case old.Description != group.Description || old.Owner != group.Owner || old.Creator != group.Creator ||
+			old.System != group.System || strings.Join(old.Tags, ",") != strings.Join(group.Tags, ","):
+			change(func() { f.setGroup(group) }, "~group %s", name)
+		}
+
+		members := make(map[string]bool)
+		for _, member := range group.Members {
+			members[member] = true
+		}
+
+		for _, member := range sortedKeys(members) {
+			member := member
+			if !f.members[name][member] {
+				change(func() { f.members[name][member] = true }, "+member %s %s", name, member)
+			}
+		}
+
+		for _, member := range sortedKeys(f.members[name]) {
+			member := member
+			if !members[member] && replace {
+				change(func() { delete(f.members[name], member) }, "-member %s %s", name, member)
+			}
+		}
+
+		services := make(map[string]bool)
+		for _, service := range group.Services {
+			services[service] = true
+		}
+
+		for _, service := range sortedKeys(services) {
+			service := service
+			if !f.owners[name][service] {
+				change(func() { f.setOwner(name, service, true) }, "+service %s %s", name, service)
+			}
+		}
+
+		for _, service := range sortedKeys(f.owners[name]) {
+			service := service
+			if !services[service] && replace {
+				change(func() { f.setOwner(name, service, false) }, "-service %s %s", name, service)
+			}
+		}
+
+		if group.Registered && !f.registered[name] {
+			change(func() { f.registered[name] = true }, "+registered %s", name)
+		}
+
+		if !group.Registered && f.registered[name] && replace {
+			change(func() { delete(f.registered, name) }, "-registered %s", name)
+		}
+
+		if old != nil && len(apply) > start {
+			apply = append(apply, func() { f.touch(name) })
+		}
+	}
+
+	admin = false
+	if replace {
+		for _, name := range sortedKeys(f.groupNames()) {
+			name := name
+			if !seen[name] && name != "server_admins" {
+				change(func() {
+					for service := range f.owners[name] {
+						delete(f.services[service], name)
+					}
+
+					delete(f.groups, name)
+					delete(f.members, name)
+					delete(f.owners, name)
+					delete(f.registered, name)
+				}, "-group %s", name)
+			}
+		}
+	}
+
+	for _, identity := range sortedKeys(identitySet(links)) {
+		identity, principal, old := identity, links[identity], f.identities[identity]
+		admin = admins[identity] || admins[principal] || admins[old]
+		if principal != old {
+			change(func() {
+				delete(f.principals[old], identity)
+				f.link(identity, principal)
+			}, "+identity %s %s", identity, principal)
+		}
+	}
+
+	if replace {
+		for _, identity := range sortedKeys(identitySet(f.identities)) {
+			identity, principal := identity, f.identities[identity]
+			admin = admins[identity] || admins[principal]
+			if _, ok := links[identity]; !ok {
+				change(func() {
+					delete(f.identities, identity)
+					delete(f.principals[principal], identity)
+				}, "-identity %s %s", identity, principal)
+			}
+		}
+	}
+
+	if len(protected) > 0 {
+		return nil, errors.Forbidden(permsrv.ReasonProtectedGroup, "Imports can't change the server_admins group or the identities of its members (%s), only `perms-srv import -admins` can.",
+			strings.Join(protected, ", "))
+	}
+
+	if !in.DryRun && len(response.Changes) > 0 {
+		for _, do := range apply {
+			do()
+		}
+
+		f.revision++
+		f.record(ctx, "Import", response.Changes...)
+	}
+
+	response.Revision = f.revision
+	return response, nil
+}
+
+// setGroup copies an imported group's description and metadata. Callers must
+// hold f.mu.
+func (f *Fake) setGroup(group *permsrv.DumpGroup) {
+	perm := f.groups[group.Name]
+	perm.Description, perm.Owner, perm.Creator = group.Description, group.Owner, group.Creator
+	perm.Tags, perm.System = group.Tags, group.System
+	perm.Created, perm.Updated = group.Created, group.Updated
+	if perm.Created == 0 {
+		perm.Created = time.Now().Unix()
+	}
+
+	if perm.Updated == 0 {
+		perm.Updated = perm.Created
+	}
+}
+
+// setOwner records (or forgets) that service declares the group. Callers
+// must hold f.mu.
+func (f *Fake) setOwner(name, service string, owns bool) {
+	if !owns {
+		delete(f.owners[name], service)
+		delete(f.services[service], name)
+		return
+	}
+
+	if f.owners[name] == nil {
+		f.owners[name] = make(map[string]bool)
+	}
+
+	if f.services[service] == nil {
+		f.services[service] = make(map[string]bool)
+	}
+
+	f.owners[name][service] = true
+	f.services[service][name] = true
+}
+
+// link links identity to principal. Callers must hold f.mu.
+func (f *Fake) link(identity, principal string) {
+	if f.principals[principal] == nil {
+		f.principals[principal] = make(map[string]bool)
+	}
+
+	f.identities[identity] = principal
+	f.principals[principal][identity] = true
+}
+
+func (f *Fake) groupNames() map[string]bool {
+	names := make(map[string]bool)
+	for name := range f.groups {
+		names[name] = true
+	}
+
+	return names
+}
+
+func identitySet(links map[string]string) map[string]bool {
+	identities := make(map[string]bool)
+	for identity := range links {
+		identities[identity] = true
+	}
+
+	return identities
+}
+
+// Check finds nothing to repair, a Fake can't get inconsistent, except for
+// a server_admins group without members.
+func (f *Fake) Check(ctx context.Context, in *permsrv.CheckRequest, opts ...client.CallOption) (*permsrv.CheckResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("Check", in); err != nil {
+		return nil, err
+	}
+
+	response := &permsrv.CheckResponse{}
+	if len(f.members["server_admins"]) == 0 {
+		response.Problems = append(response.Problems, &permsrv.CheckProblem{
+			Kind:    permsrv.CheckNoAdmins,
+			Subject: "server_admins",
+			Detail:  "There are no admins, please edit the config file and run chremoas-ctl reconfigure.",
+		})
+	}
+
+	return response, nil
+}
+
+// Health reports a Fake as always ready and at the current schema, whether
+// it has admins or not.
+func (f *Fake) Health(ctx context.Context, in *permsrv.NilRequest, opts ...client.CallOption) (*permsrv.HealthResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.call("Health", in); err != nil {
+		return nil, err
+	}
+
+	response := &permsrv.HealthResponse{
+		Ready:                 true,
+		Connected:             true,
+		SchemaVersion:         permsrv.SchemaVersion,
+		ExpectedSchemaVersion: permsrv.SchemaVersion,
+		AdminsConfigured:      len(f.members["server_admins"]) > 0,
+	}
+	if !response.AdminsConfigured {
+		response.Problems = []string{"No admins defined, please edit the config file and run chremoas-ctl reconfigure."}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"reflect"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	common "github.com/chremoas/services-common/command"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/server"
+)
+
+// DeniedMessage is what a user sees when they aren't allowed to run a command.
+const DeniedMessage = "User doesn't have permission to this command."
+
+// CommandFunc is a chat command: it's handed the sender and the arguments and
+// returns the message to send back.
+type CommandFunc func(ctx context.Context, sender string, args []string) (string, error)
+
+// Wrap only runs f if the sender holds one of the permissions. Denials are
+// answered with a SendError message, failing to ask perms-srv returns its
+// error.
+func (p Permissions) Wrap(f CommandFunc) CommandFunc {
+	return func(ctx context.Context, sender string, args []string) (string, error) {
+		canPerform, err := p.CanPerform(ctx, sender)
+		if err != nil {
+			return "", err
+		}
+
+		if !canPerform {
+			return common.SendError(DeniedMessage), nil
+		}
+
+		return f(ctx, sender, args)
+	}
+}
+
+// HandlerWrapper enforces the permissions on a go-micro server. Only the given
+// endpoints (e.g. "Command.Exec") are checked, or all of them if none are
+// given. Requests must carry the sender, which generated messages with a
+// Sender field do through GetSender.
+//
+// On denial the SendError message is written to the response's Result field
+// when it has one, as command responses do, and returned as a Forbidden error
+// otherwise. These errors carry perms-srv's reasons, perms.not_allowed and
+// perms.invalid_request for requests without a sender, for Reason to tell
+// apart.
+func (p Permissions) HandlerWrapper(endpoints ...string) server.HandlerWrapper {
+	checked := make(map[string]bool)
+	for endpoint := range endpoints {
+		checked[endpoints[endpoint]] = true
+	}
+
+	return func(fn server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			if len(checked) > 0 && !checked[req.Endpoint()] {
+				return fn(ctx, req, rsp)
+			}
+
+			body, ok := req.Body().(interface{ GetSender() string })
+			if !ok {
+				return errors.BadRequest(permsrv.ReasonInvalidRequest, "%s requests don't carry a sender.", req.Endpoint())
+			}
+
+			canPerform, err := p.CanPerform(ctx, body.GetSender())
+			if err != nil {
+				return err
+			}
+
+			if !canPerform {
+				if setResult(rsp, common.SendError(DeniedMessage)) {
+					return nil
+				}
+
+				return errors.Forbidden(permsrv.ReasonNotAllowed, "%s", common.SendError(DeniedMessage))
+			}
+
+			return fn(ctx, req, rsp)
+		}
+	}
+}
+
+func setResult(rsp interface{}, message string) bool {
+	v := reflect.ValueOf(rsp)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return false
+	}
+
+	field := v.Elem().FieldByName("Result")
+	if !field.IsValid() || !field.CanSet() {
+		return false
+	}
+
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(message)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+		field.SetBytes([]byte(message))
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	common "github.com/chremoas/services-common/command"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/server"
+)
+
+// commandRequest is what command services receive, with a sender.
+type commandRequest struct {
+	Sender string
+}
+
+func (r *commandRequest) GetSender() string { return r.Sender }
+
+type commandResponse struct {
+	Result []byte
+}
+
+// request is a go-micro request for endpoint carrying body.
+type request struct {
+	server.Request
+	endpoint string
+	body     interface{}
+}
+
+func (r *request) Service() string           { return "fleet-srv" }
+func (r *request) Endpoint() string          { return r.endpoint }
+func (r *request) Body() interface{}         { return r.body }
+func (r *request) Header() map[string]string { return nil }
+
+func newMiddlewareFake() (Permissions, *Fake) {
+	fake := NewFake("1")
+	fake.SetGroup("fleet", "Fleet commanders", "10")
+	return Permissions{Client: fake, PermissionsList: []string{"fleet"}}, fake
+}
+
+func TestWrap(t *testing.T) {
+	p, fake := newMiddlewareFake()
+	ran := false
+	command := p.Wrap(func(ctx context.Context, sender string, args []string) (string, error) {
+		ran = true
+		return "Done", nil
+	})
+
+	if message, err := command(context.Background(), "<@10>:10", nil); message != "Done" || err != nil || !ran {
+		t.Errorf("a member got %q, %v", message, err)
+	}
+
+	ran = false
+	if message, err := command(context.Background(), "<@20>:20", nil); message != common.SendError(DeniedMessage) || err != nil || ran {
+		t.Errorf("someone else got %q, %v and ran %v", message, err, ran)
+	}
+
+	fake.FailWith("Perform", errors.BadRequest(permsrv.ReasonInvalidRequest, "No."))
+	if _, err := command(context.Background(), "<@10>:10", nil); Reason(err) != permsrv.ReasonInvalidRequest || ran {
+		t.Errorf("a failing check got %v and ran %v", err, ran)
+	}
+}
+
+func TestHandlerWrapper(t *testing.T) {
+	p, _ := newMiddlewareFake()
+	ran := false
+	handler := p.HandlerWrapper("Command.Exec")(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		ran = true
+		return nil
+	})
+
+	call := func(endpoint string, body interface{}, rsp interface{}) error {
+		ran = false
+		return handler(context.Background(), &request{endpoint: endpoint, body: body}, rsp)
+	}
+
+	if err := call("Command.Exec", &commandRequest{Sender: "<@10>:10"}, &commandResponse{}); err != nil || !ran {
+		t.Errorf("a member got %v and ran %v", err, ran)
+	}
+
+	rsp := &commandResponse{}
+	if err := call("Command.Exec", &commandRequest{Sender: "<@20>:20"}, rsp); err != nil || ran || string(rsp.Result) != common.SendError(DeniedMessage) {
+		t.Errorf("someone else got %v, %q and ran %v", err, rsp.Result, ran)
+	}
+
+	// Without a Result field the denial is an error.
+	if err := call("Command.Exec", &commandRequest{Sender: "<@20>:20"}, &struct{}{}); Reason(err) != permsrv.ReasonNotAllowed || ran {
+		t.Errorf("someone else without a result got %v and ran %v", err, ran)
+	}
+
+	if err := call("Command.Exec", &struct{}{}, &commandResponse{}); Reason(err) != permsrv.ReasonInvalidRequest || ran {
+		t.Errorf("a request without a sender got %v and ran %v", err, ran)
+	}
+
+	if err := call("Command.Help", &struct{}{}, &commandResponse{}); err != nil || !ran {
+		t.Errorf("an unchecked endpoint got %v and ran %v", err, ran)
+	}
+}
@@ -3,33 +3,81 @@ package client
 import (
 	"context"
 	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
 	"strings"
 )
 
+// Platforms a sender can be identified on. Leaving Permissions.Platform empty
+// sends bare ids, which perms-srv treats as Discord users.
+const (
+	PlatformDiscord = "discord"
+	PlatformSlack   = "slack"
+	PlatformEve     = "eve"
+)
+
 // Should perform.go setup its own client?
 type Permissions struct {
 	Client          permsrv.PermissionsService
 	PermissionsList []string
+	Platform        string
 }
 
 func NewPermission(client permsrv.PermissionsService, permissionsList []string) *Permissions {
-	// TODO: Check to make sure the permissions exist and if not, create them
-	//perms, _ := client.ListPermissions(context.Background(), &permsrv.NilRequest{})
-
 	return &Permissions{Client: client, PermissionsList: permissionsList}
 }
 
+// Register declares the permission groups this command checks on behalf of
+// command in service. perms-srv creates the groups that don't exist yet, using
+// the matching entry in descriptions, and remembers that the command needs
+// them; other commands of the service keep their own declarations. An empty
+// command declares for the whole service and must list every group it
+// checks. Calling it again is harmless; commands should call it once at
+// startup.
+func (p Permissions) Register(ctx context.Context, service, command string, descriptions map[string]string) (*permsrv.RegistrationResponse, error) {
+	var perms []*permsrv.Permission
+	for perm := range p.PermissionsList {
+		perms = append(perms, &permsrv.Permission{
+			Name:        p.PermissionsList[perm],
+			Description: descriptions[p.PermissionsList[perm]],
+		})
+	}
+
+	return p.Client.RegisterPermissions(ctx,
+		&permsrv.PermissionsRegistration{
+			Service:         service,
+			Command:         command,
+			PermissionsList: perms,
+		})
+}
+
 func (p Permissions) CanPerform(ctx context.Context, sender string) (bool, error) {
-	s := strings.Split(sender, ":")
-	canPerform, err := p.Client.Perform(ctx,
+	user := sender
+	if s := strings.Split(sender, ":"); len(s) > 1 {
+		user = s[1]
+	}
+
+	if p.Platform != "" {
+		user = p.Platform + ":" + user
+	}
+
+	span, ctx := startSpan(ctx, "CanPerform")
+	defer span.Finish()
+	span.SetTag("user", user)
+	span.SetTag("permissions", strings.Join(p.PermissionsList, ","))
+
+	canPerform, err := p.Client.Perform(inject(ctx, span),
 		&permsrv.PermissionsRequest{
-			User:            s[1],
+			User:            user,
 			PermissionsList: p.PermissionsList,
 		})
 
 	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(otlog.Error(err))
 		return false, err
 	}
 
+	span.SetTag("can_perform", canPerform.CanPerform)
 	return canPerform.CanPerform, nil
 }
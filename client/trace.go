@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/metadata"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// perms-srv continues the trace its callers send along with their requests.
+// CanPerform always sends it; command services making other calls can wrap
+// their go-micro client with TraceWrapper. Tests can record the spans in
+// memory with opentracing's mocktracer.
+
+// TraceWrapper starts a span with tracer for every call made through the
+// client and sends it along.
+func TraceWrapper(tracer opentracing.Tracer) client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &traceClient{Client: c, tracer: tracer}
+	}
+}
+
+type traceClient struct {
+	client.Client
+	tracer opentracing.Tracer
+}
+
+func (c *traceClient) Call(ctx context.Context, request client.Request, response interface{}, opts ...client.CallOption) error {
+	var parent opentracing.SpanContext
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		parent = span.Context()
+	}
+
+	span := c.tracer.StartSpan(request.Service()+" "+request.Endpoint(), opentracing.ChildOf(parent), ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	err := c.Client.Call(inject(opentracing.ContextWithSpan(ctx, span), span), request, response, opts...)
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(otlog.Error(err))
+	}
+
+	return err
+}
+
+// startSpan starts a span, a child of the one in ctx and recorded by the same
+// tracer if there is one, otherwise by the global tracer.
+func startSpan(ctx context.Context, name string) (opentracing.Span, context.Context) {
+	tracer := opentracing.GlobalTracer()
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		tracer = parent.Tracer()
+	}
+
+	return opentracing.StartSpanFromContextWithTracer(ctx, tracer, name)
+}
+
+// inject adds span to the metadata go-micro sends along with calls made with
+// ctx.
+func inject(ctx context.Context, span opentracing.Span) context.Context {
+	existing, _ := metadata.FromContext(ctx)
+	md := metadata.Copy(existing)
+
+	if err := span.Tracer().Inject(span.Context(), opentracing.TextMap, opentracing.TextMapCarrier(md)); err != nil {
+		return ctx
+	}
+
+	return metadata.NewContext(ctx, md)
+}
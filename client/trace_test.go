@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/metadata"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+// recordingService answers Perform and keeps the metadata it was sent.
+type recordingService struct {
+	permsrv.PermissionsService
+	md metadata.Metadata
+}
+
+func (s *recordingService) Perform(ctx context.Context, in *permsrv.PermissionsRequest, opts ...client.CallOption) (*permsrv.PerformResponse, error) {
+	s.md, _ = metadata.FromContext(ctx)
+	return &permsrv.PerformResponse{CanPerform: true}, nil
+}
+
+// recordingClient fails every call and keeps the metadata it was sent.
+type recordingClient struct {
+	client.Client
+	md metadata.Metadata
+}
+
+func (c *recordingClient) Call(ctx context.Context, request client.Request, response interface{}, opts ...client.CallOption) error {
+	c.md, _ = metadata.FromContext(ctx)
+	return errors.New("unreachable")
+}
+
+// sent returns the span context carried in md.
+func sent(t *testing.T, tracer *mocktracer.MockTracer, md metadata.Metadata) mocktracer.MockSpanContext {
+	sc, err := tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(md))
+	if err != nil {
+		t.Fatalf("no span in %v: %v", md, err)
+	}
+
+	return sc.(mocktracer.MockSpanContext)
+}
+
+func TestCanPerformSendsItsSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	root := tracer.StartSpan("command")
+	service := &recordingService{}
+	p := Permissions{Client: service, PermissionsList: []string{"fleet", "doctrine"}, Platform: "discord"}
+
+	if ok, err := p.CanPerform(opentracing.ContextWithSpan(context.Background(), root), "<@1>:1"); !ok || err != nil {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 || spans[0].OperationName != "CanPerform" {
+		t.Fatalf("got spans %v, want CanPerform", spans)
+	}
+
+	span := spans[0]
+	if span.ParentID != root.(*mocktracer.MockSpan).SpanContext.SpanID {
+		t.Error("CanPerform isn't a child of the caller's span")
+	}
+
+	if got := sent(t, tracer, service.md); got.SpanID != span.SpanContext.SpanID || got.TraceID != span.SpanContext.TraceID {
+		t.Errorf("sent span %d of trace %d, want %d of %d", got.SpanID, got.TraceID, span.SpanContext.SpanID, span.SpanContext.TraceID)
+	}
+
+	for tag, want := range map[string]interface{}{"user": "discord:1", "permissions": "fleet,doctrine", "can_perform": true} {
+		if got := span.Tag(tag); got != want {
+			t.Errorf("got %s %v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestTraceWrapperSendsItsSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	root := tracer.StartSpan("command")
+	recorder := &recordingClient{Client: client.NewClient()}
+	c := TraceWrapper(tracer)(recorder)
+
+	request := c.NewRequest("perms", "Permissions.Perform", &permsrv.PermissionsRequest{})
+	if err := c.Call(opentracing.ContextWithSpan(context.Background(), root), request, &permsrv.PerformResponse{}); err == nil {
+		t.Fatal("want the call's error")
+	}
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 || spans[0].OperationName != "perms Permissions.Perform" {
+		t.Fatalf("got spans %v, want perms Permissions.Perform", spans)
+	}
+
+	span := spans[0]
+	if span.ParentID != root.(*mocktracer.MockSpan).SpanContext.SpanID {
+		t.Error("the call's span isn't a child of the caller's span")
+	}
+
+	if got := sent(t, tracer, recorder.md); got.SpanID != span.SpanContext.SpanID {
+		t.Errorf("sent span %d, want %d", got.SpanID, span.SpanContext.SpanID)
+	}
+
+	if span.Tag(string(ext.SpanKind)) != ext.SpanKindRPCClientEnum || span.Tag("error") != true {
+		t.Errorf("got tags %v, want a failed client span", span.Tags())
+	}
+}
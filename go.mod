@@ -3,11 +3,18 @@ module github.com/chremoas/perms-srv
 go 1.14
 
 require (
+	github.com/alicebob/miniredis/v2 v2.30.0
 	github.com/chremoas/services-common v1.3.2
+	github.com/go-redis/redis v6.15.2+incompatible
 	github.com/golang/protobuf v1.3.2
 	github.com/micro/go-micro v1.9.1
+	github.com/opentracing/opentracing-go v1.2.0
+	github.com/prometheus/client_golang v1.1.0
+	github.com/prometheus/client_model v0.0.0-20190129233127-fd36f4220a90
+	github.com/spf13/viper v1.4.0
 	go.uber.org/zap v1.10.0
 	golang.org/x/net v0.0.0-20190724013045-ca1201d0de80
+	gopkg.in/yaml.v2 v2.2.2
 )
 
 replace github.com/chremoas/perms-srv => ../perms-srv
@@ -1,88 +1,240 @@
 package handler
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/chremoas/services-common/config"
 	redis "github.com/chremoas/services-common/redis"
-	common "github.com/chremoas/services-common/command"
+	goredis "github.com/go-redis/redis"
+	"github.com/micro/go-micro/errors"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
 	"golang.org/x/net/context"
 	"strings"
-	"github.com/chremoas/services-common/config"
+	"sync"
+	"sync/atomic"
 )
 
 type permissionsHandler struct {
 	//Client client.Client
 	Redis *redis.Client
+	// actor is who the request being answered was made for, written to
+	// the audit log.
+	actor string
+	// The copies made to trace a request share the rest.
+	*handlerState
 }
 
-func NewPermissionsHandler(config *config.Configuration) permsrv.PermissionsHandler {
+type handlerState struct {
+	// unreachableUntil is when (in Unix nanoseconds) Perform tries the
+	// store again after it failed. It comes first to be aligned for atomic.
+	unreachableUntil int64
+	// ready is set (to 1) once the store is reachable and migrated.
+	ready int32
+	// decisions and outage decide Perform while the store is unreachable.
+	decisions *decisionCache
+	outage    outagePolicy
+	// groups holds the names Perform was asked about that turned out to be
+	// groups, the only ones the decision metrics are labelled with.
+	groups sync.Map
+	log    *zap.Logger
+}
+
+func NewPermissionsHandler(config *config.Configuration, logger *zap.Logger) permsrv.PermissionsHandler {
 	//addr := fmt.Sprintf("%s:%d", config.Redis.Host, config.Redis.Port)
 	redisClient := redis.Init(config.LookupService("srv", "perms"))
 
-	_, err := redisClient.Client.Ping().Result()
+	outage, err := loadOutagePolicy()
 	if err != nil {
 		panic(err)
 	}
 
-	exists, err := redisClient.Client.Exists(redisClient.KeyName("description:server_admins")).Result()
+	h := &permissionsHandler{
+		Redis: redisClient,
+		handlerState: &handlerState{
+			decisions: newDecisionCache(viper.GetInt("perms.outage.cacheSize"), viper.GetDuration("perms.outage.cacheAge")),
+			outage:    outage,
+			log:       logger,
+		},
+	}
+
+	h.instrument()
+
+	// Dying doesn't bring Redis back, we keep trying instead and Health says
+	// we aren't ready until it's there.
+	if err := h.prepare(); err != nil {
+		h.log.Warn("Not ready", zap.Error(err))
+		go h.keepPreparing()
+	}
+
+	return h
+}
 
+// prepare checks the store is there and migrates it. A schema newer than ours
+// is the only thing we refuse to start with.
+func (h *permissionsHandler) prepare() error {
+	_, err := h.Redis.Client.Ping().Result()
 	if err != nil {
-		fmt.Println(err)
+		return err
+	}
+
+	exists, err := h.Redis.Client.Exists(h.Redis.KeyName("description:server_admins")).Result()
+
+	if err != nil {
+		h.log.Error("Couldn't look for the server_admins group", zap.Error(err))
 	}
 
 	if exists == 0 {
-		fmt.Println("Permissions not setup, please edit the config file and run chremoas-ctl reconfigure")
+		h.log.Warn("Permissions not setup, please edit the config file and run chremoas-ctl reconfigure")
 	}
 
-	admins, err := redisClient.Client.SMembers(redisClient.KeyName("members:server_admins")).Result()
+	admins, err := h.Redis.Client.SMembers(h.Redis.KeyName("members:server_admins")).Result()
 
 	if len(admins) == 0 {
-		fmt.Println("No admins defined, please edit the config file and run chremoas-ctl reconfigure")
+		h.log.Warn("No admins defined, please edit the config file and run chremoas-ctl reconfigure")
+	}
+
+	if err = h.migrate(); err != nil {
+		if _, ok := err.(newerSchemaError); ok {
+			panic(err)
+		}
+
+		return err
 	}
 
-	return &permissionsHandler{Redis: redisClient}
+	atomic.StoreInt32(&h.ready, 1)
+	return nil
 }
 
 func (h *permissionsHandler) Perform(ctx context.Context, request *permsrv.PermissionsRequest, response *permsrv.PerformResponse) error {
-	serverAdmins := h.Redis.KeyName("members:server_admins")
-	isServerAdmin, err := h.Redis.Client.SIsMember(serverAdmins, request.User).Result()
+	h = h.withContext(ctx)
 
+	canPerform, err := h.perform([]*permsrv.PermissionsRequest{request})
 	if err != nil {
 		return err
 	}
 
-	// Doesn't matter what other permissions you have. If you are a server_admin you are god.
-	if isServerAdmin {
-		response.CanPerform = true
-		return nil
+	response.CanPerform = canPerform[0]
+	return nil
+}
+
+// maxBatchSize is the most requests PerformBatch decides at once, every one
+// of them is a round trip's worth of work for Redis.
+const maxBatchSize = 1000
+
+func (h *permissionsHandler) PerformBatch(ctx context.Context, request *permsrv.PerformBatchRequest, response *permsrv.PerformBatchResponse) error {
+	h = h.withContext(ctx)
+
+	if len(request.Requests) > maxBatchSize {
+		return invalidRequest(fmt.Sprintf("A batch can't hold more than %d requests.", maxBatchSize))
 	}
 
-	for perm := range request.PermissionsList {
-		permName := h.Redis.KeyName(fmt.Sprintf("members:%s", request.PermissionsList[perm]))
-		isMember, err := h.Redis.Client.SIsMember(permName, request.User).Result()
+	canPerform, err := h.perform(request.Requests)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return err
+	for i := range canPerform {
+		response.Responses = append(response.Responses, &permsrv.PerformResponse{CanPerform: canPerform[i]})
+	}
+
+	return nil
+}
+
+// decide decides a batch of requests with a single pipelined round trip for
+// the memberships on top of the ones needed to resolve who everyone is. It
+// also says which requests were allowed only because the user is an admin.
+func (h *permissionsHandler) decide(requests []*permsrv.PermissionsRequest) ([]bool, []bool, error) {
+	// Users we can't make sense of are denied, without failing the
+	// requests of everyone else in the batch.
+	var users []string
+	var valid []int
+	for request := range requests {
+		canonicalNames(requests[request].PermissionsList)
+
+		if _, err := parseIdentity(requests[request].User); err == nil {
+			users = append(users, requests[request].User)
+			valid = append(valid, request)
 		}
+	}
 
-		if isMember {
-			response.CanPerform = true
-			return nil
+	// Grants are evaluated on the principal so it doesn't matter which of
+	// their linked identities someone is talking to us through.
+	found, err := h.subjectsOf(users)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subjects := make([][]string, len(requests))
+	for i := range valid {
+		subjects[valid[i]] = found[i]
+	}
+
+	serverAdmins := h.Redis.KeyName("members:server_admins")
+	pipe := h.Redis.Client.Pipeline()
+	checks := make([][]*goredis.BoolCmd, len(requests))
+	unknown := make(map[string]*goredis.IntCmd)
+
+	for request := range requests {
+		// Doesn't matter what other permissions you have. If you are a server_admin you are god.
+		for subject := range subjects[request] {
+			checks[request] = append(checks[request], pipe.SIsMember(serverAdmins, subjects[request][subject]))
+		}
+
+		for _, perm := range requests[request].PermissionsList {
+			permName := h.Redis.KeyName(fmt.Sprintf("members:%s", perm))
+
+			if _, known := h.groups.Load(perm); !known && unknown[perm] == nil {
+				unknown[perm] = pipe.Exists(h.Redis.KeyName(fmt.Sprintf("description:%s", perm)))
+			}
+
+			for subject := range subjects[request] {
+				checks[request] = append(checks[request], pipe.SIsMember(permName, subjects[request][subject]))
+			}
 		}
 	}
 
-	response.CanPerform = false
-	return nil
+	if _, err = pipe.Exec(); err != nil {
+		return nil, nil, err
+	}
+
+	for perm, exists := range unknown {
+		if exists.Val() > 0 {
+			h.groups.Store(perm, true)
+		}
+	}
+
+	canPerform := make([]bool, len(requests))
+	admin := make([]bool, len(requests))
+	for request := range checks {
+		for check := range checks[request] {
+			if checks[request][check].Val() {
+				canPerform[request] = true
+				admin[request] = check < len(subjects[request])
+				break
+			}
+		}
+	}
+
+	return canPerform, admin, nil
 }
 
 func (h *permissionsHandler) AddPermission(ctx context.Context, request *permsrv.Permission, response *permsrv.Permission) error {
-	permName := h.Redis.KeyName(fmt.Sprintf("description:%s", request.Name))
+	h = h.withContext(ctx)
 
-	if request.Name == "server_admins" {
-		return errors.New("You cannot add the server_admins group.")
+	if canonicalName(request.Name) == "server_admins" {
+		return protectedGroup("You cannot add the server_admins group.")
 	}
 
+	name, err := checkName(request.Name)
+	if err != nil {
+		return invalidName(err)
+	}
+
+	request.Name = name
+	permName := h.Redis.KeyName(fmt.Sprintf("description:%s", request.Name))
+
 	exists, err := h.Redis.Client.Exists(permName).Result()
 
 	if err != nil {
@@ -90,185 +242,546 @@ func (h *permissionsHandler) AddPermission(ctx context.Context, request *permsrv
 	}
 
 	if exists == 1 {
-		return fmt.Errorf("Permission group `%s` already exists.", request.Name)
+		return groupExists(request.Name)
+	}
+
+	perm := &permsrv.Permission{
+		Name:        request.Name,
+		Description: request.Description,
+		Owner:       request.Owner,
+		Creator:     request.Creator,
+		Tags:        request.Tags,
+		System:      request.System,
 	}
 
-	_, err = h.Redis.Client.Set(permName, request.Description, 0).Result()
+	_, err = h.Redis.Client.TxPipelined(func(pipe goredis.Pipeliner) error {
+		pipe.Set(permName, request.Description, 0)
+		if err := h.created(pipe, perm); err != nil {
+			return err
+		}
+
+		return h.audit(pipe, "AddPermission", request.Name)
+	})
 
 	if err != nil {
 		return err
 	}
 
-	response = request
-	return nil
+	return h.GetPermission(ctx, &permsrv.Permission{Name: request.Name}, response)
+}
+
+func (h *permissionsHandler) UpdatePermission(ctx context.Context, request *permsrv.Permission, response *permsrv.Permission) error {
+	h = h.withContext(ctx)
+
+	request.Name = canonicalName(request.Name)
+	permName := h.Redis.KeyName(fmt.Sprintf("description:%s", request.Name))
+
+	if request.Name == "server_admins" {
+		return protectedGroup("You cannot change the server_admins group.")
+	}
+
+	err := h.watch(func(tx *goredis.Tx) error {
+		exists, err := tx.Exists(permName).Result()
+
+		if err != nil {
+			return err
+		}
+
+		if exists == 0 {
+			return groupNotFound(request.Name)
+		}
+
+		if err = h.checkRevision(tx, request.Name, request.ExpectedRevision); err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			// Only what's given is changed, so callers that just fix the
+			// description or retag a group don't wipe the rest.
+			if request.Description != "" {
+				pipe.Set(permName, request.Description, 0)
+			}
+
+			if request.Owner != "" {
+				pipe.HSet(h.metaKey(request.Name), "owner", request.Owner)
+			}
+
+			if request.Tags != nil {
+				tags, err := json.Marshal(request.Tags)
+				if err != nil {
+					return err
+				}
+
+				pipe.HSet(h.metaKey(request.Name), "tags", string(tags))
+			}
+
+			h.touch(pipe, request.Name)
+			return h.audit(pipe, "UpdatePermission", request.Name)
+		})
+
+		return err
+	}, permName, h.metaKey(request.Name))
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return h.GetPermission(ctx, &permsrv.Permission{Name: request.Name}, response)
+}
+
+// RenamePermission moves everything kept under a group's name to the new
+// name in one transaction, so members and registrations come along.
+func (h *permissionsHandler) RenamePermission(ctx context.Context, request *permsrv.RenamePermissionRequest, response *permsrv.Permission) error {
+	h = h.withContext(ctx)
+
+	request.Name = canonicalName(request.Name)
+
+	if request.Name == "server_admins" || canonicalName(request.NewName) == "server_admins" {
+		return protectedGroup("You cannot rename the server_admins group.")
+	}
+
+	if request.NewName == "" {
+		return invalidRequest("Renaming a permission group needs a new name.")
+	}
+
+	newName, err := checkName(request.NewName)
+	if err != nil {
+		return invalidName(err)
+	}
+
+	if request.Name == newName {
+		return errors.BadRequest(permsrv.ReasonInvalidName, "Permission group `%s` already has that name.", request.Name)
+	}
+
+	err = h.rename(request.Name, newName, request.ExpectedRevision)
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	if err != nil {
+		return err
+	}
+
+	h.forget(request.Name, newName)
+
+	return h.GetPermission(ctx, &permsrv.Permission{Name: newName}, response)
+}
+
+// rename does the work for RenamePermission, without looking at the names.
+func (h *permissionsHandler) rename(name, newName string, expected int64) error {
+	key := func(kind, name string) string {
+		return h.Redis.KeyName(fmt.Sprintf("%s:%s", kind, name))
+	}
+
+	return h.watch(func(tx *goredis.Tx) error {
+		exists, err := tx.Exists(key("description", name)).Result()
+
+		if err != nil {
+			return err
+		}
+
+		if exists == 0 {
+			return groupNotFound(name)
+		}
+
+		if err = h.checkRevision(tx, name, expected); err != nil {
+			return err
+		}
+
+		taken, err := tx.Exists(key("description", newName), key("members", newName), key("meta", newName)).Result()
+
+		if err != nil {
+			return err
+		}
+
+		if taken > 0 {
+			return groupExists(newName)
+		}
+
+		hasMembers, err := tx.Exists(key("members", name)).Result()
+
+		if err != nil {
+			return err
+		}
+
+		hasMeta, err := tx.Exists(key("meta", name)).Result()
+
+		if err != nil {
+			return err
+		}
+
+		owners, err := tx.SMembers(key("owners", name)).Result()
+
+		if err != nil {
+			return err
+		}
+
+		registered, err := tx.SIsMember(h.Redis.KeyName("registered"), name).Result()
+
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			pipe.Rename(key("description", name), key("description", newName))
+
+			if hasMembers > 0 {
+				pipe.Rename(key("members", name), key("members", newName))
+			}
+
+			if hasMeta > 0 {
+				pipe.Rename(key("meta", name), key("meta", newName))
+			}
+
+			if len(owners) > 0 {
+				pipe.Rename(key("owners", name), key("owners", newName))
+			}
+
+			for owner := range owners {
+				pipe.SRem(key("services", owners[owner]), name)
+				pipe.SAdd(key("services", owners[owner]), newName)
+			}
+
+			if registered {
+				pipe.SRem(h.Redis.KeyName("registered"), name)
+				pipe.SAdd(h.Redis.KeyName("registered"), newName)
+			}
+
+			h.touch(pipe, newName)
+			return h.audit(pipe, "RenamePermission", name, newName)
+		})
+
+		return err
+	}, key("description", name), key("description", newName),
+		key("members", name), key("members", newName),
+		key("meta", name), key("meta", newName),
+		key("owners", name), h.Redis.KeyName("registered"))
 }
 
 func (h *permissionsHandler) AddPermissionUser(ctx context.Context, request *permsrv.PermissionUser, response *permsrv.PermissionUser) error {
+	h = h.withContext(ctx)
+
+	request.Permission = canonicalName(request.Permission)
 	permName := h.Redis.KeyName(fmt.Sprintf("members:%s", request.Permission))
 	permDesc := h.Redis.KeyName(fmt.Sprintf("description:%s", request.Permission))
 
 	if request.Permission == "server_admins" {
-		return errors.New("You cannot add users to the server_admins group.")
+		return protectedGroup("You cannot add users to the server_admins group.")
 	}
 
-	exists, err := h.Redis.Client.Exists(permDesc).Result()
-
+	subjects, err := h.subjects(request.User)
 	if err != nil {
 		return err
 	}
 
-	if exists == 0 {
-		return fmt.Errorf("Permission group `%s` doesn't exists.", request.Permission)
-	}
+	principal := subjects[0]
+
+	err = h.watch(func(tx *goredis.Tx) error {
+		exists, err := tx.Exists(permDesc).Result()
+
+		if err != nil {
+			return err
+		}
+
+		if exists == 0 {
+			return groupNotFound(request.Permission)
+		}
+
+		if err = h.checkRevision(tx, request.Permission, request.ExpectedRevision); err != nil {
+			return err
+		}
 
-	_, err = h.Redis.Client.SAdd(permName, request.User).Result()
+		isMember, err := isMemberAny(tx, permName, subjects)
+		if err != nil {
+			return err
+		}
+
+		if isMember {
+			return alreadyMember(request.User, request.Permission)
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			pipe.SAdd(permName, principal)
+			h.touch(pipe, request.Permission)
+			return h.audit(pipe, "AddPermissionUser", fmt.Sprintf("%s %s", request.Permission, principal))
+		})
+
+		return err
+	}, h.metaKey(request.Permission))
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
 
 	if err != nil {
 		return err
 	}
 
-	response = request
-	return nil
+	h.forget(request.Permission)
+
+	// subjects already made sure the user parses.
+	identity, _ := parseIdentity(request.User)
+	response.User = userString(identity)
+	response.Permission = request.Permission
+	response.Group = &permsrv.Permission{}
+	return h.GetPermission(ctx, &permsrv.Permission{Name: request.Permission}, response.Group)
 }
 
 func (h *permissionsHandler) RemovePermission(ctx context.Context, request *permsrv.Permission, response *permsrv.Permission) error {
+	h = h.withContext(ctx)
+
+	request.Name = canonicalName(request.Name)
 	permName := h.Redis.KeyName(fmt.Sprintf("description:%s", request.Name))
 	permMembers := h.Redis.KeyName(fmt.Sprintf("members:%s", request.Name))
 
 	if request.Name == "server_admins" {
-		return errors.New("You cannot delete the server_admins group.")
+		return protectedGroup("You cannot delete the server_admins group.")
 	}
 
-	exists, err := h.Redis.Client.Exists(permName).Result()
+	err := h.watch(func(tx *goredis.Tx) error {
+		// The response is the group as it was before it went away.
+		if err := h.GetPermission(ctx, &permsrv.Permission{Name: request.Name}, response); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return err
-	}
+		if err := h.checkRevision(tx, request.Name, request.ExpectedRevision); err != nil {
+			return err
+		}
 
-	if exists == 0 {
-		return fmt.Errorf("Permission group `%s` doesn't exists.", request.Name)
-	}
+		members, err := tx.SCard(permMembers).Result()
 
-	members, err := h.Redis.Client.SMembers(permMembers).Result()
+		if err != nil {
+			return err
+		}
 
-	if len(members) > 0 {
-		return fmt.Errorf("Permission group `%s` not empty.", request.Name)
-	}
+		if members > 0 {
+			return errors.Conflict(permsrv.ReasonGroupNotEmpty, "Permission group `%s` not empty.", request.Name)
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			pipe.Del(permName, h.metaKey(request.Name))
+			pipe.Incr(h.Redis.KeyName("revision"))
+			return h.audit(pipe, "RemovePermission", request.Name)
+		})
 
-	_, err = h.Redis.Client.Del(permName).Result()
+		return err
+	}, permName, permMembers, h.metaKey(request.Name))
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
 
 	if err != nil {
 		return err
 	}
 
-	response = request
-	return nil
+	return h.forgetRegistration(request.Name)
 }
 
 func (h *permissionsHandler) RemovePermissionUser(ctx context.Context, request *permsrv.PermissionUser, response *permsrv.PermissionUser) error {
+	h = h.withContext(ctx)
+
+	request.Permission = canonicalName(request.Permission)
 	permName := h.Redis.KeyName(fmt.Sprintf("members:%s", request.Permission))
 	permDesc := h.Redis.KeyName(fmt.Sprintf("description:%s", request.Permission))
 
 	if request.Permission == "server_admins" {
-		return errors.New("You cannot remove users from the server_admins group.")
+		return protectedGroup("You cannot remove users from the server_admins group.")
 	}
 
-	exists, err := h.Redis.Client.Exists(permDesc).Result()
-
+	subjects, err := h.subjects(request.User)
 	if err != nil {
 		return err
 	}
 
-	if exists == 0 {
-		return fmt.Errorf("Permission group `%s` doesn't exists.", request.Permission)
-	}
+	err = h.watch(func(tx *goredis.Tx) error {
+		exists, err := tx.Exists(permDesc).Result()
 
-	isMember, err := h.Redis.Client.SIsMember(permName, request.User).Result()
-	if !isMember {
-		return fmt.Errorf("`%s` not a member of group '%s'", request.User, request.Permission)
-	}
+		if err != nil {
+			return err
+		}
 
-	_, err = h.Redis.Client.SRem(permName, request.User).Result()
+		if exists == 0 {
+			return groupNotFound(request.Permission)
+		}
+
+		if err = h.checkRevision(tx, request.Permission, request.ExpectedRevision); err != nil {
+			return err
+		}
+
+		isMember, err := isMemberAny(tx, permName, subjects)
+		if err != nil {
+			return err
+		}
+
+		if !isMember {
+			return notMember(request.User, request.Permission)
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			pipe.SRem(permName, stringsToInterfaces(subjects)...)
+			h.touch(pipe, request.Permission)
+			return h.audit(pipe, "RemovePermissionUser", fmt.Sprintf("%s %s", request.Permission, subjects[0]))
+		})
+
+		return err
+	}, h.metaKey(request.Permission))
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
 
 	if err != nil {
 		return err
 	}
 
-	response = request
-	return nil
+	h.forget(request.Permission)
+
+	// subjects already made sure the user parses.
+	identity, _ := parseIdentity(request.User)
+	response.User = userString(identity)
+	response.Permission = request.Permission
+	response.Group = &permsrv.Permission{}
+	return h.GetPermission(ctx, &permsrv.Permission{Name: request.Permission}, response.Group)
 }
 
+// ListPermissions lists every group, ListPermissionsPage lists them a page
+// at a time.
 func (h *permissionsHandler) ListPermissions(ctx context.Context, request *permsrv.NilRequest, response *permsrv.PermissionsResponse) error {
-	perms, err := h.Redis.Client.Keys(h.Redis.KeyName("description:*")).Result()
+	return h.ListPermissionsPage(ctx, &permsrv.ListPermissionsRequest{}, response)
+}
 
+func (h *permissionsHandler) ListPermissionsPage(ctx context.Context, request *permsrv.ListPermissionsRequest, response *permsrv.PermissionsResponse) error {
+	h = h.withContext(ctx)
+
+	// Read first, so callers comparing revisions never miss a change.
+	revision, err := h.revision()
 	if err != nil {
 		return err
 	}
 
-	for perm := range perms {
-		permDescription, err := h.Redis.Client.Get(perms[perm]).Result()
+	perms, err := h.Redis.Client.Keys(h.Redis.KeyName("description:*")).Result()
 
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return err
+	}
 
-		permName := strings.Split(perms[perm], ":")
+	for perm := range perms {
+		perms[perm] = strings.TrimPrefix(perms[perm], h.Redis.KeyName("description:"))
+	}
 
-		response.PermissionsList = append(response.PermissionsList,
-			&permsrv.Permission{Name: permName[len(permName)-1], Description: permDescription})
+	page, next, err := paginate(perms, request.Page)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	response.PermissionsList, err = h.describe(page)
+	response.NextPageToken = next
+	response.Revision = revision
+	return err
 }
 
 func (h *permissionsHandler) ListPermissionUsers(ctx context.Context, request *permsrv.UsersRequest, response *permsrv.UsersResponse) error {
-	var userlist []string
+	h = h.withContext(ctx)
+
+	request.Permission = canonicalName(request.Permission)
 	permName := h.Redis.KeyName(fmt.Sprintf("members:%s", request.Permission))
 
-	users, err := h.Redis.Client.SMembers(permName).Result()
+	revision, err := h.Redis.Client.HGet(h.metaKey(request.Permission), "revision").Int64()
+
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	response.Revision = revision
+
+	members, err := h.Redis.Client.SMembers(permName).Result()
 
 	if err != nil {
 		return err
 	}
 
-	for user := range users {
-		userlist = append(userlist, users[user])
+	users, err := h.memberNames(members, request.Platform)
+	if err != nil {
+		return err
 	}
 
-	response.UserList = userlist
-	return nil
+	response.UserList, response.NextPageToken, err = paginate(users, request.Page)
+	return err
 }
 
+// ListUserPermissions lists every group the user is in,
+// ListUserPermissionsPage lists them a page at a time.
 func (h *permissionsHandler) ListUserPermissions(ctx context.Context, request *permsrv.PermissionUser, response *permsrv.PermissionsResponse) error {
+	return h.ListUserPermissionsPage(ctx, &permsrv.UserPermissionsRequest{User: request.User}, response)
+}
+
+func (h *permissionsHandler) ListUserPermissionsPage(ctx context.Context, request *permsrv.UserPermissionsRequest, response *permsrv.PermissionsResponse) error {
+	h = h.withContext(ctx)
+
+	revision, err := h.revision()
+	if err != nil {
+		return err
+	}
+
+	response.Revision = revision
+
 	perms, err := h.Redis.Client.Keys(h.Redis.KeyName("members:*")).Result()
 
 	if err != nil {
 		return err
 	}
 
+	subjects, err := h.subjects(request.User)
+	if err != nil {
+		return err
+	}
+
 	// This is expensive but shouldn't really matter as it won't be used all that much. -brian
+	pipe := h.Redis.Client.Pipeline()
+	checks := make(map[string][]*goredis.BoolCmd)
+	described := make(map[string]*goredis.IntCmd)
 	for perm := range perms {
-		permName := strings.Split(perms[perm], ":")
-
-		permDesc := h.Redis.KeyName(fmt.Sprintf("description:%s", permName[len(permName)-1]))
-		permDescription, err := h.Redis.Client.Get(permDesc).Result()
+		name := strings.TrimPrefix(perms[perm], h.Redis.KeyName("members:"))
+		if !matches(name, request.Page) {
+			continue
+		}
 
-		if err != nil {
-			return err
+		described[name] = pipe.Exists(h.Redis.KeyName(fmt.Sprintf("description:%s", name)))
+		for subject := range subjects {
+			checks[name] = append(checks[name], pipe.SIsMember(perms[perm], subjects[subject]))
 		}
+	}
 
-		userId := common.ExtractUserId(request.User)
-		isMember, err := h.Redis.Client.SIsMember(perms[perm], userId).Result()
+	if _, err = pipe.Exec(); err != nil {
+		return err
+	}
 
-		if err != nil {
-			return err
+	var memberOf []string
+	for name := range checks {
+		if described[name].Val() == 0 {
+			continue
 		}
 
-		if isMember {
-			response.PermissionsList = append(response.PermissionsList,
-				&permsrv.Permission{Name: permName[len(permName)-1], Description: permDescription})
+		for check := range checks[name] {
+			if checks[name][check].Val() {
+				memberOf = append(memberOf, name)
+				break
+			}
 		}
 	}
 
-	return nil
+	page, next, err := paginate(memberOf, request.Page)
+	if err != nil {
+		return err
+	}
+
+	response.PermissionsList, err = h.describe(page)
+	response.NextPageToken = next
+	return err
 }
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"testing"
+
+	perms "github.com/chremoas/perms-srv/client"
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"golang.org/x/net/context"
+)
+
+func TestMutationsReturnTheStoredObject(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+
+	group, err := service.AddPermission(ctx, &permsrv.Permission{Name: "Fleet", Description: "Fleet commanders", Tags: []string{"ops"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if group.Name != "fleet" || group.Description != "Fleet commanders" || group.Revision != 1 || group.Created == 0 {
+		t.Fatalf("AddPermission returned %v", group)
+	}
+
+	membership, err := service.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "<@123>", Permission: "FLEET", ExpectedRevision: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if membership.User != "123" || membership.Permission != "fleet" || membership.Group.GetRevision() != 2 {
+		t.Fatalf("AddPermissionUser returned %v", membership)
+	}
+
+	if membership.Group.Description != "Fleet commanders" || len(membership.Group.Tags) != 1 {
+		t.Fatalf("AddPermissionUser returned group %v", membership.Group)
+	}
+
+	group, err = service.UpdatePermission(ctx, &permsrv.Permission{Name: "fleet", Tags: []string{"ops", "pvp"}, ExpectedRevision: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if group.Description != "Fleet commanders" || len(group.Tags) != 2 || group.Revision != 3 {
+		t.Fatalf("UpdatePermission returned %v", group)
+	}
+
+	membership, err = service.RemovePermissionUser(ctx, &permsrv.PermissionUser{User: "discord:123", Permission: "fleet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if membership.User != "123" || membership.Group.GetRevision() != 4 {
+		t.Fatalf("RemovePermissionUser returned %v", membership)
+	}
+
+	group, err = service.RemovePermission(ctx, &permsrv.Permission{Name: "fleet", ExpectedRevision: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if group.Name != "fleet" || group.Revision != 4 {
+		t.Fatalf("RemovePermission returned %v", group)
+	}
+}
+
+func TestMutationsFailWithReasons(t *testing.T) {
+	service, _ := newTestService(t, "1")
+	ctx := context.Background()
+
+	if _, err := service.AddPermission(ctx, &permsrv.Permission{Name: "fleet"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "2", Permission: "fleet"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		call   func() error
+		reason string
+	}{
+		{"group exists", func() error {
+			_, err := service.AddPermission(ctx, &permsrv.Permission{Name: "Fleet"})
+			return err
+		}, permsrv.ReasonGroupExists},
+		{"invalid name", func() error {
+			_, err := service.AddPermission(ctx, &permsrv.Permission{Name: "fleet ops"})
+			return err
+		}, permsrv.ReasonInvalidName},
+		{"already member", func() error {
+			_, err := service.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "<@2>", Permission: "fleet"})
+			return err
+		}, permsrv.ReasonAlreadyMember},
+		{"not member", func() error {
+			_, err := service.RemovePermissionUser(ctx, &permsrv.PermissionUser{User: "3", Permission: "fleet"})
+			return err
+		}, permsrv.ReasonNotMember},
+		{"group not found", func() error {
+			_, err := service.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "3", Permission: "nope"})
+			return err
+		}, permsrv.ReasonGroupNotFound},
+		{"protected group", func() error {
+			_, err := service.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "3", Permission: "server_admins"})
+			return err
+		}, permsrv.ReasonProtectedGroup},
+		{"revision mismatch", func() error {
+			_, err := service.UpdatePermission(ctx, &permsrv.Permission{Name: "fleet", Description: "Fleet", ExpectedRevision: 1})
+			return err
+		}, permsrv.ReasonRevisionMismatch},
+		{"group not empty", func() error {
+			_, err := service.RemovePermission(ctx, &permsrv.Permission{Name: "fleet"})
+			return err
+		}, permsrv.ReasonGroupNotEmpty},
+	}
+
+	for _, test := range tests {
+		if reason := perms.Reason(test.call()); reason != test.reason {
+			t.Errorf("%s: got reason %q, want %q", test.name, reason, test.reason)
+		}
+	}
+}
+
+func TestInvalidUsersAreDeniedOneByOne(t *testing.T) {
+	service, _ := newTestService(t, "1")
+	ctx := context.Background()
+
+	if _, err := service.AddPermission(ctx, &permsrv.Permission{Name: "fleet", Description: "Fleet commanders"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "10", Permission: "fleet"}); err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := service.PerformBatch(ctx, &permsrv.PerformBatchRequest{Requests: []*permsrv.PermissionsRequest{
+		{User: "", PermissionsList: []string{"fleet"}},
+		{User: "10", PermissionsList: []string{"fleet"}},
+		{User: "irc:bob", PermissionsList: []string{"fleet"}},
+		{User: "<@1>", PermissionsList: []string{"anything"}},
+		{User: "discord:", PermissionsList: []string{"fleet"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []bool{false, true, false, true, false}
+	if len(batch.Responses) != len(want) {
+		t.Fatalf("got %d responses, want %d", len(batch.Responses), len(want))
+	}
+
+	for i := range want {
+		if batch.Responses[i].CanPerform != want[i] {
+			t.Errorf("request %d: got %v, want %v", i, batch.Responses[i].CanPerform, want[i])
+		}
+	}
+
+	allowed, err := service.Perform(ctx, &permsrv.PermissionsRequest{User: "irc:bob", PermissionsList: []string{"fleet"}})
+	if err != nil || allowed.CanPerform {
+		t.Errorf("an invalid user got %v, %v, want denied", allowed, err)
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	goredis "github.com/go-redis/redis"
+	"golang.org/x/net/context"
+)
+
+// We only keep the most recent changes around, this isn't meant to replace
+// backups.
+const (
+	auditLength       = 10000
+	defaultAuditLimit = 100
+)
+
+// audit queues an entry for a change onto pipe so it's written in the same
+// transaction as the change itself. The entry names who made the change
+// when the request says, see withContext.
+func (h *permissionsHandler) audit(pipe goredis.Pipeliner, action string, details ...string) error {
+	entry, err := json.Marshal(&permsrv.AuditEntry{
+		Time:    time.Now().Unix(),
+		Action:  action,
+		Details: details,
+		Actor:   h.actor,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	pipe.LPush(h.Redis.KeyName("audit"), entry)
+	pipe.LTrim(h.Redis.KeyName("audit"), 0, auditLength-1)
+	return nil
+}
+
+func (h *permissionsHandler) ListAuditEntries(ctx context.Context, request *permsrv.AuditRequest, response *permsrv.AuditResponse) error {
+	h = h.withContext(ctx)
+
+	limit := int64(request.Limit)
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+
+	entries, err := h.Redis.Client.LRange(h.Redis.KeyName("audit"), 0, limit-1).Result()
+
+	if err != nil {
+		return err
+	}
+
+	for entry := range entries {
+		var e permsrv.AuditEntry
+		if err = json.Unmarshal([]byte(entries[entry]), &e); err != nil {
+			return err
+		}
+
+		response.Entries = append(response.Entries, &e)
+	}
+
+	return nil
+}
@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	goredis "github.com/go-redis/redis"
+	"github.com/micro/go-micro/errors"
+	"golang.org/x/net/context"
+)
+
+var errNotApplied = errors.Conflict(permsrv.ReasonNotApplied, "Not applied, another change in the request failed.")
+
+func (h *permissionsHandler) AddPermissionUsers(ctx context.Context, request *permsrv.BulkPermissionUsers, response *permsrv.BulkPermissionUsersResponse) error {
+	return h.withContext(ctx).bulkMembership("AddPermissionUsers", request, response, true)
+}
+
+func (h *permissionsHandler) RemovePermissionUsers(ctx context.Context, request *permsrv.BulkPermissionUsers, response *permsrv.BulkPermissionUsersResponse) error {
+	return h.withContext(ctx).bulkMembership("RemovePermissionUsers", request, response, false)
+}
+
+// bulkMembership adds (or removes) every user to (or from) every permission
+// group in the request. Atomic requests either apply every change or none,
+// otherwise whatever can be applied is. Either way the response says what
+// happened to each user/group pair, with the reason code and message the
+// single user RPCs would fail with, and the changes go into a single audit
+// entry.
+func (h *permissionsHandler) bulkMembership(action string, request *permsrv.BulkPermissionUsers, response *permsrv.BulkPermissionUsersResponse, add bool) error {
+	if len(request.Users) == 0 || len(request.Permissions) == 0 {
+		return invalidRequest("Bulk changes need at least one user and one permission group.")
+	}
+
+	canonicalNames(request.Permissions)
+
+	// Whatever part of the request gets applied, decisions about these
+	// groups may have changed.
+	defer h.forget(request.Permissions...)
+
+	// Users we can't make sense of fail on their own rather than failing the
+	// whole request.
+	userErrors := make([]error, len(request.Users))
+	var users []string
+	for user := range request.Users {
+		if _, userErrors[user] = parseIdentity(request.Users[user]); userErrors[user] == nil {
+			users = append(users, request.Users[user])
+		}
+	}
+
+	resolved, err := h.subjectsOf(users)
+	if err != nil {
+		return err
+	}
+
+	subjects := make([][]string, len(request.Users))
+	for user := range request.Users {
+		if userErrors[user] == nil {
+			subjects[user], resolved = resolved[0], resolved[1:]
+		}
+	}
+
+	apply := func(c goredis.Cmdable) error {
+		response.Results = nil
+
+		for _, perm := range request.Permissions {
+			problem, err := h.membershipProblem(c, perm, add)
+			if err != nil {
+				return err
+			}
+
+			for user := range request.Users {
+				result := &permsrv.PermissionUserResult{User: request.Users[user], Permission: perm, Success: true}
+
+				failure := userErrors[user]
+				if failure == nil {
+					failure = problem
+				}
+
+				if failure == nil {
+					isMember, err := isMemberAny(c, h.Redis.KeyName(fmt.Sprintf("members:%s", perm)), subjects[user])
+					if err != nil {
+						return err
+					}
+
+					switch {
+					case add && isMember:
+						failure = alreadyMember(request.Users[user], perm)
+					case !add && !isMember:
+						failure = notMember(request.Users[user], perm)
+					}
+				}
+
+				if failure != nil {
+					result.Success, result.Error, result.Reason = false, detail(failure), reason(failure)
+				}
+
+				response.Results = append(response.Results, result)
+			}
+		}
+
+		if request.Atomic && !allSucceeded(response.Results) {
+			for _, result := range response.Results {
+				if result.Success {
+					result.Success, result.Error, result.Reason = false, detail(errNotApplied), reason(errNotApplied)
+				}
+			}
+
+			return nil
+		}
+
+		_, err := c.TxPipelined(func(pipe goredis.Pipeliner) error {
+			var details []string
+			touched := make(map[string]bool)
+			for i, result := range response.Results {
+				if !result.Success {
+					continue
+				}
+
+				if !touched[result.Permission] {
+					touched[result.Permission] = true
+					h.touch(pipe, result.Permission)
+				}
+
+				user := subjects[i%len(request.Users)]
+				permName := h.Redis.KeyName(fmt.Sprintf("members:%s", result.Permission))
+
+				if add {
+					pipe.SAdd(permName, user[0])
+				} else {
+					pipe.SRem(permName, stringsToInterfaces(user)...)
+				}
+
+				details = append(details, fmt.Sprintf("%s %s", result.Permission, user[0]))
+			}
+
+			if len(details) == 0 {
+				return nil
+			}
+
+			return h.audit(pipe, action, details...)
+		})
+
+		return err
+	}
+
+	if !request.Atomic {
+		return apply(h.Redis.Client)
+	}
+
+	var keys []string
+	for _, perm := range request.Permissions {
+		keys = append(keys,
+			h.Redis.KeyName(fmt.Sprintf("description:%s", perm)),
+			h.Redis.KeyName(fmt.Sprintf("members:%s", perm)))
+	}
+
+	err = h.watch(func(tx *goredis.Tx) error {
+		return apply(tx)
+	}, keys...)
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	return err
+}
+
+// membershipProblem returns the error refusing to add users to or remove
+// them from perm, if there is one. The second error is our own failure.
+func (h *permissionsHandler) membershipProblem(c goredis.Cmdable, perm string, add bool) (problem, err error) {
+	if perm == "server_admins" && add {
+		return protectedGroup("You cannot add users to the server_admins group."), nil
+	}
+
+	if perm == "server_admins" {
+		return protectedGroup("You cannot remove users from the server_admins group."), nil
+	}
+
+	exists, err := c.Exists(h.Redis.KeyName(fmt.Sprintf("description:%s", perm))).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exists == 0 {
+		return groupNotFound(perm), nil
+	}
+
+	return nil, nil
+}
+
+func allSucceeded(results []*permsrv.PermissionUserResult) bool {
+	for result := range results {
+		if !results[result].Success {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SetPermissionUsers makes the group's members exactly the given users, for
+// syncing with systems that know who should be in a group. Someone already in
+// the group through any of their identities stays. The response holds the
+// changes, which aren't applied for dry runs.
+func (h *permissionsHandler) SetPermissionUsers(ctx context.Context, request *permsrv.SetPermissionUsersRequest, response *permsrv.SetPermissionUsersResponse) error {
+	h = h.withContext(ctx)
+
+	request.Permission = canonicalName(request.Permission)
+
+	if request.Permission == "server_admins" {
+		return protectedGroup("You cannot change the members of the server_admins group.")
+	}
+
+	subjects, err := h.subjectsOf(request.Users)
+	if err != nil {
+		return err
+	}
+
+	permDesc := h.Redis.KeyName(fmt.Sprintf("description:%s", request.Permission))
+	permName := h.Redis.KeyName(fmt.Sprintf("members:%s", request.Permission))
+
+	err = h.watch(func(tx *goredis.Tx) error {
+		response.Added, response.Removed = nil, nil
+
+		exists, err := tx.Exists(permDesc).Result()
+
+		if err != nil {
+			return err
+		}
+
+		if exists == 0 {
+			return groupNotFound(request.Permission)
+		}
+
+		if err = h.checkRevision(tx, request.Permission, request.ExpectedRevision); err != nil {
+			return err
+		}
+
+		members, err := tx.SMembers(permName).Result()
+
+		if err != nil {
+			return err
+		}
+
+		current := make(map[string]bool)
+		for member := range members {
+			current[members[member]] = true
+		}
+
+		wanted := make(map[string]bool)
+		added := make(map[string]bool)
+		for user := range subjects {
+			present := false
+			for _, subject := range subjects[user] {
+				wanted[subject] = true
+				present = present || current[subject]
+			}
+
+			if !present && !added[subjects[user][0]] {
+				added[subjects[user][0]] = true
+				response.Added = append(response.Added, subjects[user][0])
+			}
+		}
+
+		for member := range members {
+			if !wanted[members[member]] {
+				response.Removed = append(response.Removed, members[member])
+			}
+		}
+
+		sort.Strings(response.Added)
+		sort.Strings(response.Removed)
+
+		if request.DryRun || len(response.Added)+len(response.Removed) == 0 {
+			return nil
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			var details []string
+
+			if len(response.Added) > 0 {
+				pipe.SAdd(permName, stringsToInterfaces(response.Added)...)
+			}
+
+			if len(response.Removed) > 0 {
+				pipe.SRem(permName, stringsToInterfaces(response.Removed)...)
+			}
+
+			for _, added := range response.Added {
+				details = append(details, fmt.Sprintf("+%s %s", request.Permission, added))
+			}
+
+			for _, removed := range response.Removed {
+				details = append(details, fmt.Sprintf("-%s %s", request.Permission, removed))
+			}
+
+			h.touch(pipe, request.Permission)
+			return h.audit(pipe, "SetPermissionUsers", details...)
+		})
+
+		return err
+	}, permDesc, permName, h.metaKey(request.Permission))
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	if err != nil {
+		return err
+	}
+
+	h.forget(request.Permission)
+
+	// The diff is in the form members are stored in, callers get it in the
+	// form they name users in.
+	if response.Added, err = h.memberNames(response.Added, ""); err != nil {
+		return err
+	}
+
+	if response.Removed, err = h.memberNames(response.Removed, ""); err != nil {
+		return err
+	}
+
+	sort.Strings(response.Added)
+	sort.Strings(response.Removed)
+	return nil
+}
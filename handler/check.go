@@ -0,0 +1,368 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	"golang.org/x/net/context"
+)
+
+// repairFunc queues the commands fixing a problem.
+type repairFunc func(pipe goredis.Pipeliner)
+
+// reportFunc records a problem, repair is nil for those an admin has to fix.
+type reportFunc func(kind, subject, detail string, repair repairFunc)
+
+// Check looks for inconsistencies in what we store and repairs them if asked
+// to, in one transaction. Problems only an admin can fix, like invalid names
+// or a missing server_admins group, are only reported. Groups can't contain
+// other groups, members are always users, so there are no nested references
+// to dangle.
+func (h *permissionsHandler) Check(ctx context.Context, request *permsrv.CheckRequest, response *permsrv.CheckResponse) error {
+	return h.withContext(ctx).checkStore(request, response)
+}
+
+// CheckStore checks what client holds without a running service, for the
+// admin tools.
+func CheckStore(client *redis.Client, request *permsrv.CheckRequest) (*permsrv.CheckResponse, error) {
+	response := &permsrv.CheckResponse{}
+	return response, (&permissionsHandler{Redis: client}).checkStore(request, response)
+}
+
+func (h *permissionsHandler) checkStore(request *permsrv.CheckRequest, response *permsrv.CheckResponse) error {
+	err := h.watch(func(tx *goredis.Tx) error {
+		response.Problems = nil
+		var repairs []repairFunc
+
+		err := h.check(func(kind, subject, detail string, repair repairFunc) {
+			problem := &permsrv.CheckProblem{Kind: kind, Subject: subject, Detail: detail}
+			if request.Repair && repair != nil {
+				problem.Repaired = true
+				repairs = append(repairs, repair)
+			}
+
+			response.Problems = append(response.Problems, problem)
+		})
+
+		if err != nil || len(repairs) == 0 {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			for repair := range repairs {
+				repairs[repair](pipe)
+			}
+
+			var details []string
+			touched := make(map[string]bool)
+			for _, problem := range response.Problems {
+				if !problem.Repaired {
+					continue
+				}
+
+				details = append(details, fmt.Sprintf("%s %s", problem.Kind, problem.Subject))
+
+				switch problem.Kind {
+				case permsrv.CheckLegacyMember, permsrv.CheckDuplicateMember:
+					if !touched[problem.Subject] {
+						touched[problem.Subject] = true
+						h.touch(pipe, problem.Subject)
+					}
+				}
+			}
+
+			pipe.Incr(h.Redis.KeyName("revision"))
+			return h.audit(pipe, "Check", details...)
+		})
+
+		return err
+	}, h.Redis.KeyName("revision"), h.Redis.KeyName("identities"))
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	if request.Repair {
+		h.forgetAll()
+	}
+
+	return err
+}
+
+func (h *permissionsHandler) check(report reportFunc) error {
+	current, err := h.dump()
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string]*permsrv.DumpGroup)
+	for _, group := range current.Groups {
+		groups[group.Name] = group
+	}
+
+	if err = h.checkKeys(report, groups); err != nil {
+		return err
+	}
+
+	if admins := groups["server_admins"]; admins == nil || len(admins.Members) == 0 {
+		report(permsrv.CheckNoAdmins, "server_admins",
+			"There are no admins, please edit the config file and run chremoas-ctl reconfigure.", nil)
+	}
+
+	linked := make(map[string]string)
+	for principal, identities := range current.Identities {
+		for _, identity := range identities {
+			linked[identity] = principal
+		}
+	}
+
+	for _, group := range current.Groups {
+		if group.Name != "server_admins" {
+			if name, err := checkName(group.Name); err != nil || name != group.Name {
+				report(permsrv.CheckInvalidName, group.Name, strings.TrimSuffix(nameProblem(group.Name, err), ".")+".", nil)
+			}
+		}
+
+		h.checkMembers(report, group, linked, current.Identities)
+	}
+
+	return h.checkIdentities(report, linked)
+}
+
+// checkKeys reports the keys left behind by groups that don't exist.
+func (h *permissionsHandler) checkKeys(report reportFunc, groups map[string]*permsrv.DumpGroup) error {
+	leftovers := []struct{ kind, what string }{
+		{"members", "members"},
+		{"meta", "metadata"},
+		{"owners", "registrations"},
+	}
+
+	for _, leftover := range leftovers {
+		names, err := h.keyNames(leftover.kind + ":")
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if groups[name] != nil {
+				continue
+			}
+
+			key := h.Redis.KeyName(fmt.Sprintf("%s:%s", leftover.kind, name))
+			problem := permsrv.CheckDanglingReference
+			if leftover.kind == "members" {
+				problem = permsrv.CheckOrphanMembers
+			}
+
+			report(problem, key, fmt.Sprintf("Permission group `%s` doesn't exist, but we still keep its %s.", name, leftover.what),
+				func(pipe goredis.Pipeliner) { pipe.Del(key) })
+		}
+	}
+
+	services, err := h.keyNames("services:")
+	if err != nil {
+		return err
+	}
+
+	sets := []string{h.Redis.KeyName("registered")}
+	for service := range services {
+		sets = append(sets, h.Redis.KeyName(fmt.Sprintf("services:%s", services[service])))
+	}
+
+	members, err := h.setMembers(sets)
+	if err != nil {
+		return err
+	}
+
+	for _, set := range sets {
+		for _, name := range members[set] {
+			if groups[name] != nil {
+				continue
+			}
+
+			set, name := set, name
+			report(permsrv.CheckDanglingReference, set, fmt.Sprintf("Lists permission group `%s`, which doesn't exist.", name),
+				func(pipe goredis.Pipeliner) { pipe.SRem(set, name) })
+		}
+	}
+
+	return nil
+}
+
+// checkMembers reports members we can't read, members stored as mentions or
+// bare ids and people in the group more than once.
+func (h *permissionsHandler) checkMembers(report reportFunc, group *permsrv.DumpGroup, linked map[string]string, identities map[string][]string) {
+	membersKey := h.Redis.KeyName(fmt.Sprintf("members:%s", group.Name))
+
+	// Everyone in the group, by principal, with the forms they are stored in.
+	people := make(map[string][]string)
+	canonical := make(map[string]string)
+	for _, member := range group.Members {
+		if _, ok := identities[member]; ok {
+			people[member] = append(people[member], member)
+			canonical[member] = member
+			continue
+		}
+
+		identity, err := parseIdentity(member)
+		if err != nil {
+			report(permsrv.CheckInvalidMember, group.Name, fmt.Sprintf("`%s` isn't a user: %s", member, detail(err)), nil)
+			continue
+		}
+
+		canonical[member] = identityString(identity)
+		person := canonical[member]
+		if principal, ok := linked[person]; ok {
+			person = principal
+		}
+
+		people[person] = append(people[person], member)
+	}
+
+	for _, person := range sortedNames(people) {
+		forms := people[person]
+		sort.Strings(forms)
+
+		// Keep the principal if it's there, otherwise an identity.
+		keep := canonical[forms[0]]
+		for _, form := range forms {
+			if form == person {
+				keep = person
+				break
+			}
+
+			if form == canonical[form] {
+				keep = form
+			}
+		}
+
+		var drop []string
+		for _, form := range forms {
+			if form != keep {
+				drop = append(drop, form)
+			}
+		}
+
+		if len(drop) == 0 {
+			continue
+		}
+
+		repair := func(pipe goredis.Pipeliner) {
+			pipe.SRem(membersKey, stringsToInterfaces(drop)...)
+			pipe.SAdd(membersKey, keep)
+		}
+
+		if len(forms) > 1 {
+			report(permsrv.CheckDuplicateMember, group.Name,
+				fmt.Sprintf("`%s` is in the group as %s.", keep, strings.Join(forms, ", ")), repair)
+		} else {
+			report(permsrv.CheckLegacyMember, group.Name, fmt.Sprintf("`%s` is stored as `%s`.", keep, forms[0]), repair)
+		}
+	}
+}
+
+// checkIdentities compares the identities hash, which is what counts, with
+// the identities sets of the principals.
+func (h *permissionsHandler) checkIdentities(report reportFunc, linked map[string]string) error {
+	principals, err := h.keyNames("principal:")
+	if err != nil {
+		return err
+	}
+
+	sets := make([]string, len(principals))
+	for principal := range principals {
+		sets[principal] = h.Redis.KeyName(fmt.Sprintf("principal:%s", principals[principal]))
+	}
+
+	members, err := h.setMembers(sets)
+	if err != nil {
+		return err
+	}
+
+	holders := make(map[string][]string)
+	for principal := range principals {
+		for _, identity := range members[sets[principal]] {
+			holders[identity] = append(holders[identity], principals[principal])
+		}
+	}
+
+	for _, identity := range sortedNames(holders) {
+		identity := identity
+		for _, principal := range holders[identity] {
+			if principal == linked[identity] {
+				continue
+			}
+
+			set := h.Redis.KeyName(fmt.Sprintf("principal:%s", principal))
+			repair := func(pipe goredis.Pipeliner) { pipe.SRem(set, identity) }
+
+			switch {
+			case len(holders[identity]) > 1:
+				report(permsrv.CheckDuplicateIdentity, identity,
+					fmt.Sprintf("Identity `%s` is listed by principals %s, but linked to `%s`.", identity, strings.Join(holders[identity], ", "), linked[identity]), repair)
+			default:
+				report(permsrv.CheckDanglingReference, set,
+					fmt.Sprintf("Lists identity `%s`, which is linked to `%s`.", identity, linked[identity]), repair)
+			}
+		}
+	}
+
+	for _, identity := range sortedKeys(linked) {
+		identity, principal := identity, linked[identity]
+		listed := false
+		for _, holder := range holders[identity] {
+			listed = listed || holder == principal
+		}
+
+		if !listed {
+			set := h.Redis.KeyName(fmt.Sprintf("principal:%s", principal))
+			report(permsrv.CheckDanglingReference, h.Redis.KeyName("identities"),
+				fmt.Sprintf("Links identity `%s` to `%s`, which doesn't list it.", identity, principal),
+				func(pipe goredis.Pipeliner) { pipe.SAdd(set, identity) })
+		}
+	}
+
+	return nil
+}
+
+// keyNames returns what follows prefix in the names of our keys starting
+// with it.
+func (h *permissionsHandler) keyNames(prefix string) ([]string, error) {
+	keys, err := h.Redis.Client.Keys(h.Redis.KeyName(prefix + "*")).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range keys {
+		keys[key] = strings.TrimPrefix(keys[key], h.Redis.KeyName(prefix))
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// setMembers reads many sets in one round trip.
+func (h *permissionsHandler) setMembers(keys []string) (map[string][]string, error) {
+	pipe := h.Redis.Client.Pipeline()
+	cmds := make([]*goredis.StringSliceCmd, len(keys))
+	for key := range keys {
+		cmds[key] = pipe.SMembers(keys[key])
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		return nil, err
+	}
+
+	members := make(map[string][]string)
+	for key := range keys {
+		members[keys[key]] = cmds[key].Val()
+		sort.Strings(members[keys[key]])
+	}
+
+	return members, nil
+}
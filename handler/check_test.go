@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"golang.org/x/net/context"
+)
+
+func problemKinds(response *permsrv.CheckResponse, repaired bool) []string {
+	var kinds []string
+	for _, problem := range response.Problems {
+		if problem.Repaired == repaired {
+			kinds = append(kinds, problem.Kind+" "+problem.Subject)
+		}
+	}
+
+	sort.Strings(kinds)
+	return kinds
+}
+
+func TestCheckRepairs(t *testing.T) {
+	h, m := newTestHandler(t, "1")
+	ctx := context.Background()
+
+	m.Set("perms:description:fleet", "Fleet commanders")
+	m.SAdd("perms:members:fleet", "<@10>", "discord:20", "20", "irc:bob")
+	m.Set("perms:description:Ships", "Ship fitters")
+	m.SAdd("perms:members:gone", "discord:5")
+	m.HSet("perms:meta:gone", "revision", "3")
+	m.SAdd("perms:registered", "missing")
+	m.SAdd("perms:services:fleet-srv", "missing")
+	m.HSet("perms:identities", "discord:30", "p1", "discord:31", "p1")
+	m.SAdd("perms:principal:p1", "discord:31")
+	m.SAdd("perms:principal:p2", "discord:31")
+
+	repairable := []string{
+		"dangling_reference perms:identities",
+		"dangling_reference perms:meta:gone",
+		"dangling_reference perms:registered",
+		"dangling_reference perms:services:fleet-srv",
+		"duplicate_identity discord:31",
+		"duplicate_member fleet",
+		"legacy_member fleet",
+		"orphan_members perms:members:gone",
+	}
+	unrepairable := []string{"invalid_member fleet", "invalid_name Ships"}
+
+	found := &permsrv.CheckResponse{}
+	if err := h.Check(ctx, &permsrv.CheckRequest{}, found); err != nil {
+		t.Fatal(err)
+	}
+
+	all := append(append([]string(nil), repairable...), unrepairable...)
+	sort.Strings(all)
+	if got := problemKinds(found, false); !reflect.DeepEqual(got, all) {
+		t.Errorf("found\n %q\nwant\n %q", got, all)
+	}
+
+	repaired := &permsrv.CheckResponse{}
+	if err := h.Check(ctx, &permsrv.CheckRequest{Repair: true}, repaired); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := problemKinds(repaired, true); !reflect.DeepEqual(got, repairable) {
+		t.Errorf("repaired\n %q\nwant\n %q", got, repairable)
+	}
+
+	if got := problemKinds(repaired, false); !reflect.DeepEqual(got, unrepairable) {
+		t.Errorf("left\n %q\nwant\n %q", got, unrepairable)
+	}
+
+	left := &permsrv.CheckResponse{}
+	if err := h.Check(ctx, &permsrv.CheckRequest{}, left); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := problemKinds(left, false); !reflect.DeepEqual(got, unrepairable) {
+		t.Errorf("after repairing found\n %q\nwant\n %q", got, unrepairable)
+	}
+
+	if got, _ := m.Members("perms:members:fleet"); !reflect.DeepEqual(got, []string{"discord:10", "discord:20", "irc:bob"}) {
+		t.Errorf("fleet has %v", got)
+	}
+
+	if got, _ := m.Members("perms:principal:p1"); !reflect.DeepEqual(got, []string{"discord:30", "discord:31"}) {
+		t.Errorf("p1 lists %v", got)
+	}
+
+	for _, key := range []string{"perms:members:gone", "perms:meta:gone", "perms:principal:p2"} {
+		if m.Exists(key) {
+			t.Errorf("%s is still there", key)
+		}
+	}
+
+	if registered, _ := m.Members("perms:registered"); len(registered) > 0 {
+		t.Errorf("still registered %v", registered)
+	}
+
+	audit := &permsrv.AuditResponse{}
+	if err := h.ListAuditEntries(ctx, &permsrv.AuditRequest{Limit: 1}, audit); err != nil || audit.Entries[0].Action != "Check" || len(audit.Entries[0].Details) != len(repairable) {
+		t.Errorf("audited %v, %v", audit.Entries, err)
+	}
+}
@@ -0,0 +1,364 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	perms "github.com/chremoas/perms-srv/client"
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/golang/protobuf/proto"
+	"github.com/micro/go-micro/metadata"
+	"golang.org/x/net/context"
+)
+
+// conformanceSteps is a session against perms-srv touching every RPC the Fake
+// implements. Each step's outcome is compared between client.Fake and the
+// handler, so the Fake can't drift from what perms-srv does.
+var conformanceSteps = []struct {
+	name string
+	call func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error)
+}{
+	{"add group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermission(ctx, &permsrv.Permission{Name: "Fleet", Description: "Fleet commanders", Owner: "alice", Tags: []string{"ops"}})
+	}},
+	{"add existing group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermission(ctx, &permsrv.Permission{Name: "FLEET"})
+	}},
+	{"add invalid group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermission(ctx, &permsrv.Permission{Name: "fleet ops"})
+	}},
+	{"add admin group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermission(ctx, &permsrv.Permission{Name: "server_admins"})
+	}},
+	{"add second group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermission(ctx, &permsrv.Permission{Name: "scouts", Description: "Scouts"})
+	}},
+	{"add member", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "<@10>", Permission: "Fleet", ExpectedRevision: 1})
+	}},
+	{"add member again", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "discord:10", Permission: "fleet"})
+	}},
+	{"add member at old revision", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "11", Permission: "fleet", ExpectedRevision: 1})
+	}},
+	{"add member of other platform", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "slack:U1", Permission: "fleet"})
+	}},
+	{"add member of unknown platform", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "irc:bob", Permission: "fleet"})
+	}},
+	{"add member to missing group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "10", Permission: "nope"})
+	}},
+	{"add member to admin group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "10", Permission: "server_admins"})
+	}},
+	{"link identity", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.LinkIdentity(ctx, &permsrv.IdentityLink{
+			Existing: &permsrv.Identity{Id: "20"},
+			Identity: &permsrv.Identity{Platform: "slack", Id: "U1"},
+		})
+	}},
+	{"link identity of someone else", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.LinkIdentity(ctx, &permsrv.IdentityLink{
+			Existing: &permsrv.Identity{Id: "21"},
+			Identity: &permsrv.Identity{Platform: "slack", Id: "U1"},
+		})
+	}},
+	{"get principal", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.GetPrincipal(ctx, &permsrv.Identity{Platform: "slack", Id: "U1"})
+	}},
+	{"perform through linked identity", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Perform(ctx, &permsrv.PermissionsRequest{User: "20", PermissionsList: []string{"FLEET"}})
+	}},
+	{"perform as admin", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Perform(ctx, &permsrv.PermissionsRequest{User: "<@!1>", PermissionsList: []string{"anything"}})
+	}},
+	{"link identity to admin", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.LinkIdentity(ctx, &permsrv.IdentityLink{
+			Existing: &permsrv.Identity{Id: "1"},
+			Identity: &permsrv.Identity{Platform: "slack", Id: "EVIL"},
+		})
+	}},
+	{"link admin to someone else", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.LinkIdentity(ctx, &permsrv.IdentityLink{
+			Existing: &permsrv.Identity{Platform: "slack", Id: "EVIL"},
+			Identity: &permsrv.Identity{Id: "1"},
+		})
+	}},
+	{"perform as identity refused a link to admin", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Perform(ctx, &permsrv.PermissionsRequest{User: "slack:EVIL", PermissionsList: []string{"anything"}})
+	}},
+	{"perform batch", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.PerformBatch(ctx, &permsrv.PerformBatchRequest{Requests: []*permsrv.PermissionsRequest{
+			{User: "10", PermissionsList: []string{"fleet"}},
+			{User: "30", PermissionsList: []string{"fleet", "scouts"}},
+			{User: "slack:U1", PermissionsList: []string{"scouts", "fleet"}},
+		}})
+	}},
+	{"perform batch with invalid users", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.PerformBatch(ctx, &permsrv.PerformBatchRequest{Requests: []*permsrv.PermissionsRequest{
+			{User: "", PermissionsList: []string{"fleet"}},
+			{User: "10", PermissionsList: []string{"fleet"}},
+			{User: "irc:bob", PermissionsList: []string{"fleet"}},
+		}})
+	}},
+	{"perform as invalid user", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Perform(ctx, &permsrv.PermissionsRequest{User: "irc:bob", PermissionsList: []string{"fleet"}})
+	}},
+	{"perform batch too big", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		requests := make([]*permsrv.PermissionsRequest, maxBatchSize+1)
+		for request := range requests {
+			requests[request] = &permsrv.PermissionsRequest{User: "10"}
+		}
+
+		return s.PerformBatch(ctx, &permsrv.PerformBatchRequest{Requests: requests})
+	}},
+	{"list members", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListPermissionUsers(ctx, &permsrv.UsersRequest{Permission: "fleet"})
+	}},
+	{"list members on slack", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListPermissionUsers(ctx, &permsrv.UsersRequest{Permission: "fleet", Platform: "slack"})
+	}},
+	{"list user groups", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListUserPermissions(ctx, &permsrv.PermissionUser{User: "20"})
+	}},
+	{"list groups", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListPermissions(ctx, &permsrv.NilRequest{})
+	}},
+	{"list first page of groups", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListPermissionsPage(ctx, &permsrv.ListPermissionsRequest{Page: &permsrv.PageRequest{Size: 1}})
+	}},
+	{"list filtered groups", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListPermissionsPage(ctx, &permsrv.ListPermissionsRequest{Page: &permsrv.PageRequest{Contains: "OUT"}})
+	}},
+	{"list with invalid page token", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListUserPermissionsPage(ctx, &permsrv.UserPermissionsRequest{User: "10", Page: &permsrv.PageRequest{Token: "%%"}})
+	}},
+	{"retag group for a user", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		ctx = metadata.NewContext(ctx, metadata.Metadata{httpUserHeader: "<@10>"})
+		return s.UpdatePermission(ctx, &permsrv.Permission{Name: "fleet", Tags: []string{"ops", "pvp"}})
+	}},
+	{"update at a stale revision", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.UpdatePermission(ctx, &permsrv.Permission{Name: "fleet", Description: "Fleet", ExpectedRevision: 1})
+	}},
+	{"update passing back a read group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		// Revision is what the group was at, not what the caller expects.
+		return s.UpdatePermission(ctx, &permsrv.Permission{Name: "fleet", Owner: "fleet-srv", Revision: 1})
+	}},
+	{"remove at a stale revision", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RemovePermission(ctx, &permsrv.Permission{Name: "fleet", ExpectedRevision: 1})
+	}},
+	{"rename group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RenamePermission(ctx, &permsrv.RenamePermissionRequest{Name: "fleet", NewName: "Ships"})
+	}},
+	{"rename onto existing group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RenamePermission(ctx, &permsrv.RenamePermissionRequest{Name: "ships", NewName: "scouts"})
+	}},
+	{"remove group with members", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RemovePermission(ctx, &permsrv.Permission{Name: "ships"})
+	}},
+	{"bulk add atomically with failures", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermissionUsers(ctx, &permsrv.BulkPermissionUsers{
+			Users:       []string{"10", "40", "irc:bob"},
+			Permissions: []string{"scouts", "ships", "nope"},
+			Atomic:      true,
+		})
+	}},
+	{"bulk add", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.AddPermissionUsers(ctx, &permsrv.BulkPermissionUsers{
+			Users:       []string{"10", "40"},
+			Permissions: []string{"scouts", "ships"},
+		})
+	}},
+	{"bulk remove", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RemovePermissionUsers(ctx, &permsrv.BulkPermissionUsers{
+			Users:       []string{"40", "50"},
+			Permissions: []string{"scouts", "server_admins"},
+		})
+	}},
+	{"set members dry run", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.SetPermissionUsers(ctx, &permsrv.SetPermissionUsersRequest{Permission: "ships", Users: []string{"slack:U1", "60"}, DryRun: true})
+	}},
+	{"set members", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.SetPermissionUsers(ctx, &permsrv.SetPermissionUsersRequest{Permission: "ships", Users: []string{"slack:U1", "60"}})
+	}},
+	{"list members after set", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListPermissionUsers(ctx, &permsrv.UsersRequest{Permission: "ships"})
+	}},
+	{"remove member", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RemovePermissionUser(ctx, &permsrv.PermissionUser{User: "60", Permission: "ships"})
+	}},
+	{"remove non-member", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RemovePermissionUser(ctx, &permsrv.PermissionUser{User: "60", Permission: "ships"})
+	}},
+	{"unlink identity", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.UnlinkIdentity(ctx, &permsrv.Identity{Platform: "slack", Id: "U1"})
+	}},
+	{"unlink unlinked identity", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.UnlinkIdentity(ctx, &permsrv.Identity{Platform: "slack", Id: "U1"})
+	}},
+	{"perform after unlinking", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Perform(ctx, &permsrv.PermissionsRequest{User: "slack:U1", PermissionsList: []string{"ships"}})
+	}},
+	{"register command", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RegisterPermissions(ctx, &permsrv.PermissionsRegistration{Service: "fleet-srv", Command: "ping", PermissionsList: []*permsrv.Permission{
+			{Name: "server_admins"}, {Name: "Pingers", Description: "Can ping"}, {Name: "ships"},
+		}})
+	}},
+	{"register other command", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RegisterPermissions(ctx, &permsrv.PermissionsRegistration{Service: "fleet-srv", Command: "op", PermissionsList: []*permsrv.Permission{
+			{Name: "ops", Description: "Can run ops"},
+		}})
+	}},
+	{"register command again", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RegisterPermissions(ctx, &permsrv.PermissionsRegistration{Service: "fleet-srv", Command: "ping", PermissionsList: []*permsrv.Permission{
+			{Name: "pingers"},
+		}})
+	}},
+	{"register invalid group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RegisterPermissions(ctx, &permsrv.PermissionsRegistration{Service: "fleet-srv", PermissionsList: []*permsrv.Permission{{Name: "a b"}}})
+	}},
+	{"list undeclared groups", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListUndeclaredPermissions(ctx, &permsrv.NilRequest{})
+	}},
+	{"get group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.GetPermission(ctx, &permsrv.Permission{Name: "Pingers"})
+	}},
+	{"get missing group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.GetPermission(ctx, &permsrv.Permission{Name: "nope"})
+	}},
+	{"remove group", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.RemovePermission(ctx, &permsrv.Permission{Name: "pingers"})
+	}},
+	{"export", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Export(ctx, &permsrv.ExportRequest{Format: permsrv.DumpYAML})
+	}},
+	{"export in unknown format", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Export(ctx, &permsrv.ExportRequest{Format: "xml"})
+	}},
+	{"import", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Import(ctx, &permsrv.ImportRequest{Data: []byte(`{"Version": 1, "Groups": [
+			{"Name": "Imported", "Description": "From a dump", "Members": ["discord:70"], "Services": ["fleet-srv"]},
+			{"Name": "ships", "Description": "Ship fitters"}
+		]}`)})
+	}},
+	{"import dry run", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Import(ctx, &permsrv.ImportRequest{DryRun: true, Data: []byte(`{"Version": 1, "Groups": [
+			{"Name": "imported", "Description": "Changed", "Members": ["discord:70", "discord:71"]}
+		]}`)})
+	}},
+	{"import adding an admin", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Import(ctx, &permsrv.ImportRequest{Data: []byte(`{"Version": 1, "Groups": [
+			{"Name": "server_admins", "Description": "Server Admins", "Members": ["discord:1", "discord:666"]}
+		]}`)})
+	}},
+	{"import linking an identity to an admin", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Import(ctx, &permsrv.ImportRequest{Data: []byte(`{"Version": 1, "Identities": {"evil": ["discord:1", "slack:EVIL"]}}`)})
+	}},
+	{"import of unknown version", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Import(ctx, &permsrv.ImportRequest{Data: []byte(`{"Version": 99}`)})
+	}},
+	{"import in unknown mode", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Import(ctx, &permsrv.ImportRequest{Mode: "append", Data: []byte(`{"Version": 1}`)})
+	}},
+	{"perform after refused imports", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.PerformBatch(ctx, &permsrv.PerformBatchRequest{Requests: []*permsrv.PermissionsRequest{
+			{User: "666", PermissionsList: []string{"anything"}},
+			{User: "slack:EVIL", PermissionsList: []string{"anything"}},
+			{User: "70", PermissionsList: []string{"imported"}},
+		}})
+	}},
+	{"check", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Check(ctx, &permsrv.CheckRequest{})
+	}},
+	{"check and repair", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Check(ctx, &permsrv.CheckRequest{Repair: true})
+	}},
+	{"health", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.Health(ctx, &permsrv.NilRequest{})
+	}},
+	{"audit log", func(ctx context.Context, s permsrv.PermissionsService) (proto.Message, error) {
+		return s.ListAuditEntries(ctx, &permsrv.AuditRequest{Limit: 100})
+	}},
+}
+
+func TestFakeConforms(t *testing.T) {
+	service, _ := newTestService(t, "1")
+	fake := perms.NewFake("1")
+	ctx := context.Background()
+
+	for _, step := range conformanceSteps {
+		want := conformanceOutcome(step.call(ctx, service))
+		got := conformanceOutcome(step.call(ctx, fake))
+
+		if got != want {
+			t.Errorf("%s:\n fake:      %s\n perms-srv: %s", step.name, got, want)
+		}
+	}
+}
+
+// conformanceOutcome describes what a call did, leaving out what legitimately differs
+// between runs: times, latencies and generated principal ids.
+func conformanceOutcome(response proto.Message, err error) string {
+	if err != nil {
+		return "error " + perms.Reason(err)
+	}
+
+	var groups []*permsrv.Permission
+	switch r := response.(type) {
+	case *permsrv.Permission:
+		groups = []*permsrv.Permission{r}
+	case *permsrv.PermissionUser:
+		groups = []*permsrv.Permission{r.Group}
+	case *permsrv.PermissionsResponse:
+		groups = r.PermissionsList
+	case *permsrv.RegistrationResponse:
+		groups = append(r.Created, r.Undeclared...)
+	case *permsrv.Principal:
+		r.Id = ""
+	case *permsrv.ExportResponse:
+		dump, err := permsrv.UnmarshalDump(r.Data, r.Format)
+		if err != nil {
+			return "unreadable dump " + err.Error()
+		}
+
+		var linked []string
+		for _, identities := range dump.Identities {
+			linked = append(linked, strings.Join(identities, ","))
+		}
+
+		sort.Strings(linked)
+		var dumped []string
+		for _, group := range dump.Groups {
+			group.Created, group.Updated = 0, 0
+			dumped = append(dumped, fmt.Sprintf("%+v", *group))
+		}
+
+		return fmt.Sprintf("%s dump of %v linking %v", r.Format, dumped, linked)
+	case *permsrv.HealthResponse:
+		r.LatencyMicroseconds = 0
+	case *permsrv.AuditResponse:
+		var actions []string
+		for _, entry := range r.Entries {
+			if entry.Actor != "" {
+				entry.Action += " by " + entry.Actor
+			}
+
+			actions = append(actions, entry.Action)
+		}
+
+		return strings.Join(actions, " ")
+	}
+
+	for _, group := range groups {
+		if group != nil {
+			group.Created, group.Updated = 0, 0
+		}
+	}
+
+	return fmt.Sprintf("%T %v", response, response)
+}
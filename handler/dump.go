@@ -0,0 +1,557 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	"golang.org/x/net/context"
+)
+
+// Export hands out everything we store as a single document and Import loads
+// one back, for backups and for moving groups between installations. The
+// server_admins group is exported like any other, so the admins travel with
+// the rest, but like everywhere else in the API only the admin tools can
+// change it: imports through the RPC are refused when they would change the
+// admins. Audit entries don't travel, they are only a recent history.
+
+// ExportDump exports what client holds without a running service, for the
+// admin tools.
+func ExportDump(client *redis.Client, request *permsrv.ExportRequest) (*permsrv.ExportResponse, error) {
+	response := &permsrv.ExportResponse{}
+	return response, (&permissionsHandler{Redis: client}).export(request, response)
+}
+
+// ImportDump imports into client without a running service, for the admin
+// tools. Only with admins set may the dump change the server_admins group and
+// the identities of its members.
+func ImportDump(client *redis.Client, request *permsrv.ImportRequest, admins bool) (*permsrv.ImportResponse, error) {
+	response := &permsrv.ImportResponse{}
+	return response, (&permissionsHandler{Redis: client}).importDump(request, response, admins)
+}
+
+func (h *permissionsHandler) Export(ctx context.Context, request *permsrv.ExportRequest, response *permsrv.ExportResponse) error {
+	return h.withContext(ctx).export(request, response)
+}
+
+func (h *permissionsHandler) export(request *permsrv.ExportRequest, response *permsrv.ExportResponse) error {
+	format, err := permsrv.DumpFormat(request.Format)
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+
+	dump, err := h.dump()
+	if err != nil {
+		return err
+	}
+
+	response.Data, err = dump.Marshal(format)
+	response.Format = format
+	return err
+}
+
+// Import loads an exported database. Merging adds and updates groups,
+// members, registrations and identities but never removes any, replacing
+// also removes whatever isn't in the dump. The response lists the changes,
+// which aren't applied for dry runs.
+func (h *permissionsHandler) Import(ctx context.Context, request *permsrv.ImportRequest, response *permsrv.ImportResponse) error {
+	return h.withContext(ctx).importDump(request, response, false)
+}
+
+// importDump imports a dump, refusing to change the admins unless admins is
+// set.
+func (h *permissionsHandler) importDump(request *permsrv.ImportRequest, response *permsrv.ImportResponse, admins bool) error {
+	mode := strings.ToLower(request.Mode)
+	if mode == "" {
+		mode = permsrv.ImportMerge
+	}
+
+	if mode != permsrv.ImportMerge && mode != permsrv.ImportReplace {
+		return invalidRequest(fmt.Sprintf("Unknown import mode `%s`, use merge or replace.", request.Mode))
+	}
+
+	wanted, err := permsrv.UnmarshalDump(request.Data, request.Format)
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+
+	if err = checkDump(wanted); err != nil {
+		return err
+	}
+
+	err = h.watch(func(tx *goredis.Tx) error {
+		current, err := h.dump()
+		if err != nil {
+			return err
+		}
+
+		plan := h.planImport(current, wanted, mode == permsrv.ImportReplace)
+
+		response.Changes = nil
+		var protected []string
+		for change := range plan {
+			response.Changes = append(response.Changes, plan[change].change)
+			if plan[change].admin {
+				protected = append(protected, plan[change].change)
+			}
+		}
+
+		if len(protected) > 0 && !admins {
+			return protectedGroup(fmt.Sprintf("Imports can't change the server_admins group or the identities of its members (%s), only `perms-srv import -admins` can.",
+				strings.Join(protected, ", ")))
+		}
+
+		if request.DryRun || len(plan) == 0 {
+			return nil
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			for change := range plan {
+				plan[change].apply(pipe)
+			}
+
+			pipe.Incr(h.Redis.KeyName("revision"))
+			return h.audit(pipe, "Import", response.Changes...)
+		})
+
+		return err
+	}, h.Redis.KeyName("revision"), h.Redis.KeyName("identities"))
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !request.DryRun {
+		h.forgetAll()
+	}
+
+	response.Revision, err = h.revision()
+	return err
+}
+
+// checkDump makes sure a dump is something we could have written, putting
+// names and identities in their canonical form on the way.
+func checkDump(dump *permsrv.Dump) error {
+	if dump.Version != permsrv.DumpVersion {
+		return invalidRequest(fmt.Sprintf("Can't import dumps of version %d, only version %d.", dump.Version, permsrv.DumpVersion))
+	}
+
+	seen := make(map[string]bool)
+	for _, group := range dump.Groups {
+		if group == nil {
+			return invalidRequest("The dump has an empty group.")
+		}
+
+		group.Name = canonicalName(group.Name)
+		if group.Name != "server_admins" {
+			if _, err := checkName(group.Name); err != nil {
+				return invalidName(err)
+			}
+		}
+
+		if seen[group.Name] {
+			return invalidRequest(fmt.Sprintf("Permission group `%s` is in the dump twice.", group.Name))
+		}
+
+		seen[group.Name] = true
+
+		for member := range group.Members {
+			if strings.TrimSpace(group.Members[member]) == "" {
+				return invalidRequest(fmt.Sprintf("Permission group `%s` has an empty member.", group.Name))
+			}
+		}
+	}
+
+	linked := make(map[string]bool)
+	for principal, identities := range dump.Identities {
+		for i := range identities {
+			identity, err := parseIdentity(identities[i])
+			if err != nil {
+				return err
+			}
+
+			identities[i] = identityString(identity)
+			if linked[identities[i]] {
+				return invalidRequest(fmt.Sprintf("Identity `%s` is linked to more than one principal.", identities[i]))
+			}
+
+			linked[identities[i]] = true
+		}
+
+		if principal == "" {
+			return invalidRequest("The dump has identities without a principal.")
+		}
+	}
+
+	return nil
+}
+
+// dump reads the whole database.
+func (h *permissionsHandler) dump() (*permsrv.Dump, error) {
+	keys, err := h.Redis.Client.Keys(h.Redis.KeyName("description:*")).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(keys))
+	for key := range keys {
+		names[key] = strings.TrimPrefix(keys[key], h.Redis.KeyName("description:"))
+	}
+
+	sort.Strings(names)
+
+	perms, err := h.describe(names)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := h.Redis.Client.Pipeline()
+	members := make([]*goredis.StringSliceCmd, len(perms))
+	owners := make([]*goredis.StringSliceCmd, len(perms))
+	registered := make([]*goredis.BoolCmd, len(perms))
+	for perm := range perms {
+		members[perm] = pipe.SMembers(h.Redis.KeyName(fmt.Sprintf("members:%s", perms[perm].Name)))
+		owners[perm] = pipe.SMembers(h.Redis.KeyName(fmt.Sprintf("owners:%s", perms[perm].Name)))
+		registered[perm] = pipe.SIsMember(h.Redis.KeyName("registered"), perms[perm].Name)
+	}
+	identities := pipe.HGetAll(h.Redis.KeyName("identities"))
+
+	if _, err = pipe.Exec(); err != nil {
+		return nil, err
+	}
+
+	dump := &permsrv.Dump{Version: permsrv.DumpVersion, Exported: time.Now().Unix()}
+	for perm := range perms {
+		group := &permsrv.DumpGroup{
+			Name:        perms[perm].Name,
+			Description: perms[perm].Description,
+			Owner:       perms[perm].Owner,
+			Creator:     perms[perm].Creator,
+			Created:     perms[perm].Created,
+			Updated:     perms[perm].Updated,
+			Tags:        perms[perm].Tags,
+			System:      perms[perm].System,
+			Members:     members[perm].Val(),
+			Services:    owners[perm].Val(),
+			Registered:  registered[perm].Val(),
+		}
+
+		sort.Strings(group.Members)
+		sort.Strings(group.Services)
+		dump.Groups = append(dump.Groups, group)
+	}
+
+	for identity, principal := range identities.Val() {
+		if dump.Identities == nil {
+			dump.Identities = make(map[string][]string)
+		}
+
+		dump.Identities[principal] = append(dump.Identities[principal], identity)
+	}
+
+	for principal := range dump.Identities {
+		sort.Strings(dump.Identities[principal])
+	}
+
+	return dump, nil
+}
+
+// importStep is one change an import makes, as shown to the caller and as
+// queued onto the transaction applying it. Changes to who is an admin are
+// marked, only the admin tools may make them.
+type importStep struct {
+	change string
+	apply  func(pipe goredis.Pipeliner)
+	admin  bool
+}
+
+// planImport works out what it takes to get from current to wanted.
+func (h *permissionsHandler) planImport(current, wanted *permsrv.Dump, replace bool) []importStep {
+	var plan []importStep
+	admin := false
+	step := func(apply func(pipe goredis.Pipeliner), format string, args ...interface{}) {
+		plan = append(plan, importStep{change: fmt.Sprintf(format, args...), apply: apply, admin: admin})
+	}
+
+	// Linking an identity to an admin's principal, or an admin's identity to
+	// someone else, makes admins as surely as adding members does.
+	admins := make(map[string]bool)
+	for _, dump := range []*permsrv.Dump{current, wanted} {
+		for _, group := range dump.Groups {
+			if group.Name == "server_admins" {
+				for _, member := range group.Members {
+					admins[member] = true
+				}
+			}
+		}
+	}
+
+	for _, dump := range []*permsrv.Dump{current, wanted} {
+		for principal, identities := range dump.Identities {
+			for _, identity := range identities {
+				if admins[identity] {
+					admins[principal] = true
+				}
+			}
+		}
+	}
+
+	existing := make(map[string]*permsrv.DumpGroup)
+	for _, group := range current.Groups {
+		existing[group.Name] = group
+	}
+
+	kept := make(map[string]bool)
+	for _, group := range wanted.Groups {
+		group := group
+		kept[group.Name] = true
+		old := existing[group.Name]
+		start := len(plan)
+		admin = group.Name == "server_admins"
+
+		switch {
+		case old == nil:
+			step(func(pipe goredis.Pipeliner) { h.importGroup(pipe, group, 1) }, "+group %s", group.Name)
+			old = &permsrv.DumpGroup{Name: group.Name}
+		case !sameGroup(old, group):
+			step(func(pipe goredis.Pipeliner) { h.importGroup(pipe, group, 0) }, "~group %s", group.Name)
+		}
+
+		membersKey := h.Redis.KeyName(fmt.Sprintf("members:%s", group.Name))
+		added, removed := diffStrings(old.Members, group.Members)
+		for _, member := range added {
+			member := member
+			step(func(pipe goredis.Pipeliner) { pipe.SAdd(membersKey, member) }, "+member %s %s", group.Name, member)
+		}
+
+		for _, member := range removed {
+			member := member
+			if replace {
+				step(func(pipe goredis.Pipeliner) { pipe.SRem(membersKey, member) }, "-member %s %s", group.Name, member)
+			}
+		}
+
+		added, removed = diffStrings(old.Services, group.Services)
+		for _, service := range added {
+			service := service
+			step(func(pipe goredis.Pipeliner) { h.importService(pipe, group.Name, service, true) }, "+service %s %s", group.Name, service)
+		}
+
+		for _, service := range removed {
+			service := service
+			if replace {
+				step(func(pipe goredis.Pipeliner) { h.importService(pipe, group.Name, service, false) }, "-service %s %s", group.Name, service)
+			}
+		}
+
+		if group.Registered && !old.Registered {
+			step(func(pipe goredis.Pipeliner) { pipe.SAdd(h.Redis.KeyName("registered"), group.Name) }, "+registered %s", group.Name)
+		}
+
+		if !group.Registered && old.Registered && replace {
+			step(func(pipe goredis.Pipeliner) { pipe.SRem(h.Redis.KeyName("registered"), group.Name) }, "-registered %s", group.Name)
+		}
+
+		// Existing groups that change at all get a new revision, once.
+		if existing[group.Name] != nil && len(plan) > start {
+			apply := plan[start].apply
+			plan[start].apply = func(pipe goredis.Pipeliner) {
+				apply(pipe)
+				h.touch(pipe, group.Name)
+			}
+		}
+	}
+
+	admin = false
+	if replace {
+		for _, group := range current.Groups {
+			group := group
+			// Without server_admins nobody could fix anything afterwards, a
+			// dump has to replace it rather than leave it out.
+			if !kept[group.Name] && group.Name != "server_admins" {
+				step(func(pipe goredis.Pipeliner) { h.dropGroup(pipe, group) }, "-group %s", group.Name)
+			}
+		}
+	}
+
+	linked := make(map[string]string)
+	for principal, identities := range current.Identities {
+		for _, identity := range identities {
+			linked[identity] = principal
+		}
+	}
+
+	wantedLinks := make(map[string]string)
+	for principal, identities := range wanted.Identities {
+		for _, identity := range identities {
+			wantedLinks[identity] = principal
+		}
+	}
+
+	for _, identity := range sortedKeys(wantedLinks) {
+		identity, principal, old := identity, wantedLinks[identity], linked[identity]
+		if principal == old {
+			continue
+		}
+
+		admin = admins[identity] || admins[principal] || admins[old]
+
+		step(func(pipe goredis.Pipeliner) {
+			if old != "" {
+				pipe.SRem(h.Redis.KeyName(fmt.Sprintf("principal:%s", old)), identity)
+			}
+
+			pipe.HSet(h.Redis.KeyName("identities"), identity, principal)
+			pipe.SAdd(h.Redis.KeyName(fmt.Sprintf("principal:%s", principal)), identity)
+		}, "+identity %s %s", identity, principal)
+	}
+
+	if replace {
+		for _, identity := range sortedKeys(linked) {
+			identity, principal := identity, linked[identity]
+			if _, ok := wantedLinks[identity]; ok {
+				continue
+			}
+
+			admin = admins[identity] || admins[principal]
+
+			step(func(pipe goredis.Pipeliner) {
+				pipe.HDel(h.Redis.KeyName("identities"), identity)
+				pipe.SRem(h.Redis.KeyName(fmt.Sprintf("principal:%s", principal)), identity)
+			}, "-identity %s %s", identity, principal)
+		}
+	}
+
+	return plan
+}
+
+// importGroup queues writing a group's description and metadata. New groups
+// start at the given revision, existing ones (0) keep theirs until touched.
+func (h *permissionsHandler) importGroup(pipe goredis.Pipeliner, group *permsrv.DumpGroup, revision int64) {
+	tags, _ := json.Marshal(group.Tags)
+
+	now := time.Now().Unix()
+	created, updated := group.Created, group.Updated
+	if created == 0 {
+		created = now
+	}
+
+	if updated == 0 {
+		updated = created
+	}
+
+	meta := map[string]interface{}{
+		"owner":   group.Owner,
+		"creator": group.Creator,
+		"created": created,
+		"updated": updated,
+		"tags":    string(tags),
+		"system":  fmt.Sprintf("%t", group.System),
+	}
+
+	if revision > 0 {
+		meta["revision"] = revision
+	}
+
+	pipe.Set(h.Redis.KeyName(fmt.Sprintf("description:%s", group.Name)), group.Description, 0)
+	pipe.HMSet(h.metaKey(group.Name), meta)
+}
+
+func (h *permissionsHandler) importService(pipe goredis.Pipeliner, name, service string, add bool) {
+	ownersKey := h.Redis.KeyName(fmt.Sprintf("owners:%s", name))
+	serviceKey := h.Redis.KeyName(fmt.Sprintf("services:%s", service))
+
+	if add {
+		pipe.SAdd(ownersKey, service)
+		pipe.SAdd(serviceKey, name)
+	} else {
+		pipe.SRem(ownersKey, service)
+		pipe.SRem(serviceKey, name)
+	}
+}
+
+// dropGroup queues removing a group with everything we keep about it.
+func (h *permissionsHandler) dropGroup(pipe goredis.Pipeliner, group *permsrv.DumpGroup) {
+	pipe.Del(
+		h.Redis.KeyName(fmt.Sprintf("description:%s", group.Name)),
+		h.Redis.KeyName(fmt.Sprintf("members:%s", group.Name)),
+		h.Redis.KeyName(fmt.Sprintf("owners:%s", group.Name)),
+		h.metaKey(group.Name))
+
+	for _, service := range group.Services {
+		pipe.SRem(h.Redis.KeyName(fmt.Sprintf("services:%s", service)), group.Name)
+	}
+
+	pipe.SRem(h.Redis.KeyName("registered"), group.Name)
+}
+
+// sameGroup reports whether two groups have the same description and
+// metadata. Times aren't compared, they change by themselves.
+func sameGroup(a, b *permsrv.DumpGroup) bool {
+	if a.Description != b.Description || a.Owner != b.Owner || a.Creator != b.Creator || a.System != b.System {
+		return false
+	}
+
+	if len(a.Tags) != len(b.Tags) {
+		return false
+	}
+
+	for tag := range a.Tags {
+		if a.Tags[tag] != b.Tags[tag] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// diffStrings returns what is in b but not a, and what is in a but not b,
+// both sorted.
+func diffStrings(a, b []string) ([]string, []string) {
+	inA := make(map[string]bool)
+	for i := range a {
+		inA[a[i]] = true
+	}
+
+	inB := make(map[string]bool)
+	for i := range b {
+		inB[b[i]] = true
+	}
+
+	var added, removed []string
+	for s := range inB {
+		if !inA[s] {
+			added = append(added, s)
+		}
+	}
+
+	for s := range inA {
+		if !inB[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
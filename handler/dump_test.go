@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+
+	perms "github.com/chremoas/perms-srv/client"
+	permsrv "github.com/chremoas/perms-srv/proto"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	"golang.org/x/net/context"
+)
+
+func TestImportCantChangeAdmins(t *testing.T) {
+	service, m := newTestService(t, "1")
+	ctx := context.Background()
+
+	exported, err := service.Export(ctx, &permsrv.ExportRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dump is what's stored, changed by change.
+	dump := func(change func(dump *permsrv.Dump)) []byte {
+		current, err := permsrv.UnmarshalDump(exported.Data, exported.Format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		change(current)
+		data, err := json.Marshal(current)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return data
+	}
+
+	addAdmin := dump(func(dump *permsrv.Dump) {
+		dump.Groups[0].Members = append(dump.Groups[0].Members, "discord:2")
+	})
+
+	tests := []struct {
+		name string
+		data []byte
+		mode string
+	}{
+		{"adding an admin", addAdmin, permsrv.ImportMerge},
+		{"removing an admin", dump(func(dump *permsrv.Dump) {
+			dump.Groups[0].Members = nil
+		}), permsrv.ImportReplace},
+		{"linking an identity to an admin", dump(func(dump *permsrv.Dump) {
+			dump.Identities = map[string][]string{"p1": {"discord:1", "slack:U2"}}
+		}), permsrv.ImportMerge},
+	}
+
+	for _, test := range tests {
+		_, err := service.Import(ctx, &permsrv.ImportRequest{Data: test.data, Mode: test.mode})
+		if reason := perms.Reason(err); reason != permsrv.ReasonProtectedGroup {
+			t.Errorf("%s: got reason %q, want %q", test.name, reason, permsrv.ReasonProtectedGroup)
+		}
+	}
+
+	allowed, err := service.Perform(ctx, &permsrv.PermissionsRequest{User: "2", PermissionsList: []string{"anything"}})
+	if err != nil || allowed.CanPerform {
+		t.Fatalf("refused imports made an admin: %v, %v", allowed, err)
+	}
+
+	client := &redis.Client{Client: goredis.NewClient(&goredis.Options{Addr: m.Addr()}), Prefix: "perms"}
+	if _, err = ImportDump(client, &permsrv.ImportRequest{Data: addAdmin}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	allowed, err = service.Perform(ctx, &permsrv.PermissionsRequest{User: "2", PermissionsList: []string{"anything"}})
+	if err != nil || !allowed.CanPerform {
+		t.Fatalf("the admin import didn't make an admin: %v, %v", allowed, err)
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/errors"
+)
+
+// Everything we refuse to do is answered with a go-micro error whose Id is
+// one of the permsrv.Reason constants, anything else is our own failure.
+
+var errChanged = errors.Conflict(permsrv.ReasonChanged, "The permission groups changed while applying the request, please try again.")
+
+var errAdminIdentity = protectedGroup("The identities of server_admins members can't be linked or unlinked through the API, only `perms-srv import -admins` can change them.")
+
+func groupNotFound(name string) error {
+	return errors.NotFound(permsrv.ReasonGroupNotFound, "Permission group `%s` doesn't exists.", name)
+}
+
+func groupExists(name string) error {
+	return errors.Conflict(permsrv.ReasonGroupExists, "Permission group `%s` already exists.", name)
+}
+
+func alreadyMember(user, name string) error {
+	return errors.Conflict(permsrv.ReasonAlreadyMember, "`%s` is already a member of group '%s'", user, name)
+}
+
+func notMember(user, name string) error {
+	return errors.NotFound(permsrv.ReasonNotMember, "`%s` not a member of group '%s'", user, name)
+}
+
+func protectedGroup(message string) error {
+	return errors.Forbidden(permsrv.ReasonProtectedGroup, "%s", message)
+}
+
+func invalidRequest(message string) error {
+	return errors.BadRequest(permsrv.ReasonInvalidRequest, "%s", message)
+}
+
+func invalidName(err error) error {
+	return errors.BadRequest(permsrv.ReasonInvalidName, "%s", err)
+}
+
+// reason is the reason code of an error, empty for our own failures.
+func reason(err error) string {
+	if e, ok := err.(*errors.Error); ok {
+		return e.Id
+	}
+
+	return ""
+}
+
+// detail is the message of an error without the go-micro envelope, for
+// places that report errors as plain strings.
+func detail(err error) string {
+	if e, ok := err.(*errors.Error); ok {
+		return e.Detail
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	debugproto "github.com/micro/go-micro/debug/proto"
+	"github.com/micro/go-micro/server"
+	"golang.org/x/net/context"
+)
+
+// Health tells orchestration whether to send us traffic. We're ready once
+// Redis answers and is migrated to our schema. Missing admins are reported
+// but don't make us unready, chremoas-ctl needs us up to fix that.
+func (h *permissionsHandler) Health(ctx context.Context, request *permsrv.NilRequest, response *permsrv.HealthResponse) error {
+	h = h.withContext(ctx)
+
+	response.ExpectedSchemaVersion = schemaVersion
+
+	start := time.Now()
+	_, err := h.Redis.Client.Ping().Result()
+	response.LatencyMicroseconds = int64(time.Since(start) / time.Microsecond)
+
+	if err != nil {
+		response.Problems = append(response.Problems, fmt.Sprintf("Redis isn't reachable: %s.", err))
+		return nil
+	}
+
+	response.Connected = true
+
+	if response.SchemaVersion, err = h.schema(); err != nil {
+		response.Problems = append(response.Problems, fmt.Sprintf("Couldn't read the schema version: %s.", err))
+	} else if response.SchemaVersion != schemaVersion {
+		response.Problems = append(response.Problems, fmt.Sprintf("The schema is at version %d, not %d.", response.SchemaVersion, schemaVersion))
+	}
+
+	admins, err := h.Redis.Client.SCard(h.Redis.KeyName("members:server_admins")).Result()
+
+	if err != nil {
+		response.Problems = append(response.Problems, fmt.Sprintf("Couldn't count the admins: %s.", err))
+	}
+
+	response.AdminsConfigured = admins > 0
+	if !response.AdminsConfigured {
+		response.Problems = append(response.Problems, "No admins defined, please edit the config file and run chremoas-ctl reconfigure.")
+	}
+
+	prepared := atomic.LoadInt32(&h.ready) == 1
+	if !prepared {
+		response.Problems = append(response.Problems, "Still starting up.")
+	}
+
+	response.Ready = prepared && err == nil && response.SchemaVersion == schemaVersion
+	return nil
+}
+
+// HealthWrapper makes go-micro's Debug.Health, which always says "ok", answer
+// from perms' Health instead, so `micro health` and the like only see us
+// healthy when we're ready, and otherwise why not.
+func HealthWrapper(perms permsrv.PermissionsHandler) server.HandlerWrapper {
+	return func(fn server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, request server.Request, response interface{}) error {
+			status, ok := response.(*debugproto.HealthResponse)
+			if !ok || request.Endpoint() != "Debug.Health" {
+				return fn(ctx, request, response)
+			}
+
+			health := &permsrv.HealthResponse{}
+			if err := perms.Health(ctx, &permsrv.NilRequest{}, health); err != nil {
+				return err
+			}
+
+			status.Status = "ok"
+			if !health.Ready {
+				status.Status = "not ready: " + strings.Join(health.Problems, " ")
+			}
+
+			return nil
+		}
+	}
+}
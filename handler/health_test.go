@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	debugproto "github.com/micro/go-micro/debug/proto"
+	"github.com/micro/go-micro/server"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+// debugRequest is go-micro's Debug.Health request.
+type debugRequest struct{ server.Request }
+
+func (debugRequest) Endpoint() string { return "Debug.Health" }
+
+func health(t *testing.T, h *permissionsHandler) *permsrv.HealthResponse {
+	response := &permsrv.HealthResponse{}
+	if err := h.Health(context.Background(), &permsrv.NilRequest{}, response); err != nil {
+		t.Fatal(err)
+	}
+
+	response.LatencyMicroseconds = 0
+	return response
+}
+
+func debugHealth(t *testing.T, h *permissionsHandler) string {
+	status := &debugproto.HealthResponse{}
+	err := HealthWrapper(h)(func(ctx context.Context, request server.Request, response interface{}) error {
+		t.Error("go-micro's Debug.Health answered")
+		return nil
+	})(context.Background(), debugRequest{}, status)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return status.Status
+}
+
+func TestReadyWithoutAdmins(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	want := &permsrv.HealthResponse{
+		Ready:                 true,
+		Connected:             true,
+		SchemaVersion:         schemaVersion,
+		ExpectedSchemaVersion: schemaVersion,
+		Problems:              []string{"No admins defined, please edit the config file and run chremoas-ctl reconfigure."},
+	}
+
+	// chremoas-ctl needs us up to add the admins.
+	if got := health(t, h); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if status := debugHealth(t, h); status != "ok" {
+		t.Errorf("Debug.Health said %q", status)
+	}
+}
+
+func TestNotReady(t *testing.T) {
+	h, m := newTestHandler(t, "1")
+
+	viper.Set("perms.http.token", testToken)
+	t.Cleanup(func() { viper.Set("perms.http.token", "") })
+
+	api, err := NewHTTPHandler(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		fail    func()
+		problem string
+	}{
+		{"starting up", func() { atomic.StoreInt32(&h.ready, 0) }, "Still starting up."},
+		{"behind on the schema", func() { m.Set("perms:schema", "1") }, "The schema is at version 1, not 2."},
+		{"without Redis", m.Close, "Redis isn't reachable"},
+	}
+
+	// Each failure comes on top of the ones before.
+	for _, test := range tests {
+		test.fail()
+
+		got := health(t, h)
+		if got.Ready || !strings.Contains(strings.Join(got.Problems, "\n"), test.problem) {
+			t.Errorf("%s: got %+v", test.name, got)
+		}
+
+		if status := debugHealth(t, h); status == "ok" {
+			t.Errorf("%s: Debug.Health said ok", test.name)
+		}
+
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, httptest.NewRequest("GET", "/v1/health", nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: GET /v1/health got %d", test.name, w.Code)
+		}
+	}
+
+	if got := health(t, h); got.Connected || got.AdminsConfigured {
+		t.Errorf("without Redis got %+v", got)
+	}
+}
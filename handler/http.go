@@ -0,0 +1,604 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/metadata"
+	"github.com/micro/go-micro/server"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+// The HTTP API serves every RPC as JSON over HTTP, for the web dashboard.
+// main serves it on net.listenHost:net.listenPort when a port is configured,
+// and it needs a token:
+//
+//	perms:
+//	  http:
+//	    token: <secret>       callers send Authorization: Bearer <secret>,
+//	                          at least 32 characters
+//	    permissions:          the groups allowed to make changes
+//	      - server_admins
+//
+// Services calling it with the token name the user they act for in
+// X-Perms-User. The web UI never gets the token, people sign in to it with
+// keys of their own and it acts as them, see session.go. Reads only need the
+// token or a session; changes need the user to hold one of the permissions,
+// like the chat commands changing permissions. Requests go through the same
+// wrappers as RPCs, so they are logged, measured and traced alike.
+//
+// Bodies are the JSON of the proto messages. Path segments like {Name} and
+// query parameters (in snake_case, paging ones without the page_ prefix) set
+// the fields of the same name. GET /v1/openapi.json describes it all, and
+// GET / is the web UI.
+const (
+	httpUserHeader = "X-Perms-User"
+	// The web UI sends this along with its session cookie. Other sites can
+	// get browsers to send the cookie, but not this header.
+	uiHeader    = "X-Perms-Ui"
+	sessionPath = "/ui/session"
+	maxHTTPBody = 16 << 20
+	// Long enough not to be guessed, or to be a word someone typed in.
+	minTokenLength = 32
+)
+
+// Headers saying who is calling, which only authenticate reads.
+var credentialHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	httpUserHeader:  true,
+}
+
+// access says who may call a route.
+type access int
+
+const (
+	public access = iota
+	reader
+	changer
+)
+
+type route struct {
+	method   string
+	path     string
+	endpoint string
+	access   access
+}
+
+// Routes without an endpoint serve one of the documents, or the web UI's
+// session.
+var routes = []route{
+	{"GET", "/", "", public},
+	{"GET", "/v1/openapi.json", "", public},
+	{"POST", sessionPath, "", public},
+	{"GET", sessionPath, "", public},
+	{"DELETE", sessionPath, "", public},
+	{"GET", "/v1/health", "Health", public},
+	{"POST", "/v1/perform", "Perform", reader},
+	{"POST", "/v1/perform/batch", "PerformBatch", reader},
+	{"GET", "/v1/groups", "ListPermissionsPage", reader},
+	{"POST", "/v1/groups", "AddPermission", changer},
+	{"GET", "/v1/groups/{Name}", "GetPermission", reader},
+	{"PATCH", "/v1/groups/{Name}", "UpdatePermission", changer},
+	{"DELETE", "/v1/groups/{Name}", "RemovePermission", changer},
+	{"POST", "/v1/groups/{Name}/rename", "RenamePermission", changer},
+	{"GET", "/v1/groups/{Permission}/members", "ListPermissionUsers", reader},
+	{"POST", "/v1/groups/{Permission}/members", "AddPermissionUser", changer},
+	{"PUT", "/v1/groups/{Permission}/members", "SetPermissionUsers", changer},
+	{"DELETE", "/v1/groups/{Permission}/members/{User}", "RemovePermissionUser", changer},
+	{"POST", "/v1/members/add", "AddPermissionUsers", changer},
+	{"POST", "/v1/members/remove", "RemovePermissionUsers", changer},
+	{"GET", "/v1/users/{User}/groups", "ListUserPermissionsPage", reader},
+	{"POST", "/v1/identities", "LinkIdentity", changer},
+	{"GET", "/v1/identities/{Platform}/{Id}", "GetPrincipal", reader},
+	{"DELETE", "/v1/identities/{Platform}/{Id}", "UnlinkIdentity", changer},
+	{"POST", "/v1/registrations", "RegisterPermissions", changer},
+	{"GET", "/v1/undeclared", "ListUndeclaredPermissions", reader},
+	{"GET", "/v1/audit", "ListAuditEntries", reader},
+	{"GET", "/v1/export", "Export", reader},
+	{"POST", "/v1/import", "Import", changer},
+	{"POST", "/v1/check", "Check", changer},
+}
+
+type httpAPI struct {
+	perms       permsrv.PermissionsHandler
+	sessions    sessionStore
+	call        server.HandlerFunc
+	token       string
+	permissions []string
+	documents   map[string]document
+}
+
+// sessionStore keeps the web UI's sessions.
+type sessionStore interface {
+	openSession(ctx context.Context, key string) (string, string, error)
+	sessionUser(ctx context.Context, id string) (string, error)
+	closeSession(ctx context.Context, id string) error
+}
+
+type document struct {
+	contentType string
+	headers     map[string]string
+	body        []byte
+}
+
+// NewHTTPHandler returns the HTTP API calling perms through wrappers.
+func NewHTTPHandler(perms permsrv.PermissionsHandler, wrappers ...server.HandlerWrapper) (http.Handler, error) {
+	api := &httpAPI{
+		perms:       perms,
+		token:       viper.GetString("perms.http.token"),
+		permissions: viper.GetStringSlice("perms.http.permissions"),
+	}
+
+	if len(api.token) < minTokenLength {
+		return nil, fmt.Errorf("The HTTP API needs a token of at least %d characters, set perms.http.token.", minTokenLength)
+	}
+
+	sessions, ok := perms.(sessionStore)
+	if !ok {
+		return nil, fmt.Errorf("The HTTP API only serves the handler from NewPermissionsHandler.")
+	}
+
+	api.sessions = sessions
+
+	if len(api.permissions) == 0 {
+		api.permissions = []string{"server_admins"}
+	}
+
+	spec, err := json.MarshalIndent(openAPI(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	api.documents = map[string]document{
+		"/":                {contentType: uiContentType, headers: map[string]string{"Content-Security-Policy": uiSecurityPolicy}, body: []byte(uiPage)},
+		"/v1/openapi.json": {contentType: "application/json", body: spec},
+	}
+
+	handlers := reflect.ValueOf(perms)
+	api.call = func(ctx context.Context, request server.Request, response interface{}) error {
+		method := handlers.MethodByName(strings.TrimPrefix(request.Endpoint(), "Permissions."))
+		results := method.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(request.Body()), reflect.ValueOf(response)})
+
+		err, _ := results[0].Interface().(error)
+		return err
+	}
+
+	for wrapper := len(wrappers); wrapper > 0; wrapper-- {
+		api.call = wrappers[wrapper-1](api.call)
+	}
+
+	return api, nil
+}
+
+func (api *httpAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, params, err := findRoute(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if route.path == sessionPath {
+		api.serveSession(w, r)
+		return
+	}
+
+	if route.endpoint == "" {
+		doc := api.documents[route.path]
+		for key, value := range doc.headers {
+			w.Header().Set(key, value)
+		}
+
+		w.Header().Set("Content-Type", doc.contentType)
+		w.Write(doc.body)
+		return
+	}
+
+	// Everything but the credentials goes along like go-micro metadata, so
+	// request ids and traces carry over. The user is the one they name.
+	md := metadata.Metadata{}
+	for key := range r.Header {
+		if !credentialHeaders[key] {
+			md[key] = r.Header.Get(key)
+		}
+	}
+
+	ctx := metadata.NewContext(r.Context(), md)
+
+	user, err := api.authenticate(ctx, r, route)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if user != "" {
+		md[httpUserHeader] = user
+	}
+
+	if err = api.authorize(ctx, route, user); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	requestType, responseType := endpointTypes(route.endpoint)
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPBody))
+	if err != nil {
+		writeError(w, invalidRequest(fmt.Sprintf("Couldn't read the body: %s", err)))
+		return
+	}
+
+	request, err := decodeRequest(requestType, body, r.URL.Query(), params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := reflect.New(responseType.Elem()).Interface()
+	err = api.call(ctx, &httpRequest{endpoint: "Permissions." + route.endpoint, body: request, raw: body, header: md}, response)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Load balancers only look at the status.
+	status := http.StatusOK
+	if health, ok := response.(*permsrv.HealthResponse); ok && !health.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, response)
+}
+
+// authenticate returns who is calling: the user a caller with the token
+// names in X-Perms-User, or the one the web UI's session belongs to.
+func (api *httpAPI) authenticate(ctx context.Context, r *http.Request, route route) (string, error) {
+	if route.access == public {
+		return "", nil
+	}
+
+	if _, err := r.Cookie(sessionCookie); err == nil && r.Header.Get("Authorization") == "" {
+		return api.sessionUser(ctx, r)
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(api.token)) != 1 {
+		return "", errors.Unauthorized(permsrv.ReasonUnauthorized, "Send the API token as `Authorization: Bearer <token>`, or sign in to the web UI.")
+	}
+
+	return r.Header.Get(httpUserHeader), nil
+}
+
+// authorize refuses changes by users that don't hold one of the permissions.
+func (api *httpAPI) authorize(ctx context.Context, route route, user string) error {
+	if route.access != changer {
+		return nil
+	}
+
+	if user == "" {
+		return errors.Unauthorized(permsrv.ReasonUnauthorized, "Name the user making the change in %s.", httpUserHeader)
+	}
+
+	decision := &permsrv.PerformResponse{}
+	request := &permsrv.PermissionsRequest{User: user, PermissionsList: append([]string(nil), api.permissions...)}
+	if err := api.perms.Perform(ctx, request, decision); err != nil {
+		return err
+	}
+
+	if !decision.CanPerform {
+		return errors.Forbidden(permsrv.ReasonNotAllowed, "`%s` isn't allowed to change permissions.", user)
+	}
+
+	return nil
+}
+
+// sessionUser returns who the web UI session r carries belongs to.
+func (api *httpAPI) sessionUser(ctx context.Context, r *http.Request) (string, error) {
+	if r.Header.Get(uiHeader) == "" {
+		return "", errors.Unauthorized(permsrv.ReasonUnauthorized, "Requests with the session cookie need the %s header.", uiHeader)
+	}
+
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return "", errSignedOut
+	}
+
+	return api.sessions.sessionUser(ctx, cookie.Value)
+}
+
+// serveSession signs the web UI in with a UI key, says who it's signed in as
+// and signs it out.
+func (api *httpAPI) serveSession(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(uiHeader) == "" {
+		writeError(w, errors.Unauthorized(permsrv.ReasonUnauthorized, "Only the web UI signs in, with the %s header.", uiHeader))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var request struct{ Key string }
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHTTPBody)).Decode(&request); err != nil || request.Key == "" {
+			writeError(w, invalidRequest("Send the UI key as `{\"Key\": \"<key>\"}`."))
+			return
+		}
+
+		id, user, err := api.sessions.openSession(r.Context(), request.Key)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		http.SetCookie(w, newSessionCookie(r, id, sessionAge))
+		writeJSON(w, http.StatusOK, map[string]string{"User": user})
+	case http.MethodGet:
+		user, err := api.sessionUser(r.Context(), r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"User": user})
+	case http.MethodDelete:
+		if cookie, err := r.Cookie(sessionCookie); err == nil {
+			if err = api.sessions.closeSession(r.Context(), cookie.Value); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+
+		http.SetCookie(w, newSessionCookie(r, "", -1))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// newSessionCookie is the cookie holding the session id, only sent back to
+// us, over TLS when the UI was loaded over it, and out of the page's reach.
+// A negative age removes it.
+func newSessionCookie(r *http.Request, id string, age time.Duration) *http.Cookie {
+	maxAge := int(age / time.Second)
+	if age < 0 {
+		maxAge = -1
+	}
+
+	return &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+// findRoute returns the route matching the request and the values of its
+// path parameters.
+func findRoute(r *http.Request) (route, map[string]string, error) {
+	segments := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	var allowed []string
+
+	for _, route := range routes {
+		params, ok := matchPath(route.path, segments)
+		if !ok {
+			continue
+		}
+
+		if route.method == r.Method {
+			return route, params, nil
+		}
+
+		allowed = append(allowed, route.method)
+	}
+
+	if len(allowed) > 0 {
+		return route{}, nil, errors.MethodNotAllowed(permsrv.ReasonInvalidRequest, "%s only accepts %s.", r.URL.Path, strings.Join(allowed, ", "))
+	}
+
+	return route{}, nil, errors.NotFound(permsrv.ReasonInvalidRequest, "There is nothing at %s.", r.URL.Path)
+}
+
+func matchPath(path string, segments []string) (map[string]string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != len(segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for part := range parts {
+		segment, err := url.PathUnescape(segments[part])
+		if err != nil {
+			return nil, false
+		}
+
+		switch {
+		case strings.HasPrefix(parts[part], "{"):
+			params[strings.Trim(parts[part], "{}")] = segment
+		case parts[part] != segment:
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// endpointTypes returns the request and response types of an RPC.
+func endpointTypes(endpoint string) (reflect.Type, reflect.Type) {
+	method, _ := reflect.TypeOf((*permsrv.PermissionsHandler)(nil)).Elem().MethodByName(endpoint)
+	return method.Type.In(1), method.Type.In(2)
+}
+
+// decodeRequest builds the request from the body, then sets the fields named
+// in the query and the path.
+func decodeRequest(requestType reflect.Type, body []byte, query url.Values, params map[string]string) (interface{}, error) {
+	request := reflect.New(requestType.Elem())
+
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, request.Interface()); err != nil {
+			return nil, invalidRequest(fmt.Sprintf("The body isn't a valid %s: %s", requestType.Elem().Name(), err))
+		}
+	}
+
+	for _, field := range queryFields(requestType) {
+		if value := query.Get(field.param); value != "" {
+			if err := setField(request.Elem(), field.path, value); err != nil {
+				return nil, invalidRequest(fmt.Sprintf("Invalid %s: %s", field.param, err))
+			}
+		}
+	}
+
+	for name, value := range params {
+		if err := setField(request.Elem(), []string{name}, value); err != nil {
+			return nil, invalidRequest(fmt.Sprintf("Invalid %s: %s", name, err))
+		}
+	}
+
+	return request.Interface(), nil
+}
+
+// queryField is a field that can be set from the query, path has the names
+// of the fields leading to it.
+type queryField struct {
+	param string
+	path  []string
+	kind  reflect.Kind
+}
+
+// queryFields are the scalar fields of a request, and of the messages in it
+// like its page.
+func queryFields(requestType reflect.Type) []queryField {
+	var fields []queryField
+
+	for _, field := range protoFields(requestType.Elem()) {
+		switch {
+		case isScalar(field.Type):
+			fields = append(fields, queryField{param: snakeCase(field.Name), path: []string{field.Name}, kind: field.Type.Kind()})
+		case field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct:
+			for _, inner := range protoFields(field.Type.Elem()) {
+				if isScalar(inner.Type) {
+					fields = append(fields, queryField{param: snakeCase(inner.Name), path: []string{field.Name, inner.Name}, kind: inner.Type.Kind()})
+				}
+			}
+		}
+	}
+
+	return fields
+}
+
+// protoFields are the fields of a message that come from the proto.
+func protoFields(message reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for field := 0; field < message.NumField(); field++ {
+		if _, ok := message.Field(field).Tag.Lookup("protobuf"); ok {
+			fields = append(fields, message.Field(field))
+		}
+	}
+
+	return fields
+}
+
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int32, reflect.Int64:
+		return true
+	}
+
+	return false
+}
+
+func setField(message reflect.Value, path []string, value string) error {
+	field := message.FieldByName(path[0])
+	if !field.IsValid() {
+		return fmt.Errorf("no such field")
+	}
+
+	if len(path) > 1 {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+
+		return setField(field.Elem(), path[1:], value)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(b)
+	case reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(i)
+	default:
+		return fmt.Errorf("can't be set from a string")
+	}
+
+	return nil
+}
+
+func snakeCase(name string) string {
+	var snake strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			snake.WriteByte('_')
+		}
+
+		snake.WriteRune(r)
+	}
+
+	return strings.ToLower(snake.String())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with the go-micro error, whose code is an HTTP status.
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*errors.Error)
+	if !ok || e.Code == 0 {
+		e = errors.InternalServerError("", "%s", detail(err)).(*errors.Error)
+	}
+
+	writeJSON(w, int(e.Code), e)
+}
+
+// httpRequest is an HTTP request as go-micro hands RPCs to handler wrappers.
+type httpRequest struct {
+	endpoint string
+	body     interface{}
+	raw      []byte
+	header   map[string]string
+}
+
+func (r *httpRequest) Service() string           { return "" }
+func (r *httpRequest) Method() string            { return r.endpoint }
+func (r *httpRequest) Endpoint() string          { return r.endpoint }
+func (r *httpRequest) ContentType() string       { return "application/json" }
+func (r *httpRequest) Header() map[string]string { return r.header }
+func (r *httpRequest) Body() interface{}         { return r.body }
+func (r *httpRequest) Read() ([]byte, error)     { return r.raw, nil }
+func (r *httpRequest) Codec() codec.Reader       { return nil }
+func (r *httpRequest) Stream() bool              { return false }
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+const testToken = "0123456789abcdef0123456789abcdef"
+
+func TestHTTPNeedsALongToken(t *testing.T) {
+	h, _ := newTestHandler(t)
+	t.Cleanup(func() { viper.Set("perms.http.token", "") })
+
+	for _, token := range []string{"", "a long random secret"} {
+		viper.Set("perms.http.token", token)
+		if _, err := NewHTTPHandler(h); err == nil {
+			t.Errorf("served the HTTP API with the token %q", token)
+		}
+	}
+}
+
+func TestHTTPChangesNeedAnAllowedUser(t *testing.T) {
+	h, m := newTestHandler(t, "1")
+	m.Set("perms:description:helpers", "Helpers")
+	m.SAdd("perms:members:helpers", "discord:2")
+	m.HSet("perms:identities", "discord:1", "p1", "slack:U1", "p1")
+	m.SAdd("perms:principal:p1", "discord:1", "slack:U1")
+
+	viper.Set("perms.http.token", testToken)
+	viper.Set("perms.http.permissions", []string{"helpers"})
+	t.Cleanup(func() {
+		viper.Set("perms.http.token", "")
+		viper.Set("perms.http.permissions", nil)
+	})
+
+	api, err := NewHTTPHandler(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers map[string]string
+		status  int
+		reason  string
+	}{
+		{"reading without the token", "GET", "/v1/groups", "", nil, http.StatusUnauthorized, permsrv.ReasonUnauthorized},
+		{"reading with a wrong token", "GET", "/v1/groups", "", map[string]string{"Authorization": "Bearer guess"}, http.StatusUnauthorized, permsrv.ReasonUnauthorized},
+		{"reading with the token", "GET", "/v1/groups", "", map[string]string{"Authorization": "Bearer " + testToken}, http.StatusOK, ""},
+		{"changing without a user", "POST", "/v1/groups", `{"Name": "fleet"}`,
+			map[string]string{"Authorization": "Bearer " + testToken}, http.StatusUnauthorized, permsrv.ReasonUnauthorized},
+		{"changing as a user outside the groups", "POST", "/v1/groups", `{"Name": "fleet"}`,
+			map[string]string{"Authorization": "Bearer " + testToken, httpUserHeader: "3"}, http.StatusForbidden, permsrv.ReasonNotAllowed},
+		{"changing as a user in the groups", "POST", "/v1/groups", `{"Name": "fleet"}`,
+			map[string]string{"Authorization": "Bearer " + testToken, httpUserHeader: "2"}, http.StatusOK, ""},
+		{"linking oneself to an admin", "POST", "/v1/identities",
+			`{"Existing": {"Platform": "discord", "Id": "1"}, "Identity": {"Platform": "discord", "Id": "2"}}`,
+			map[string]string{"Authorization": "Bearer " + testToken, httpUserHeader: "2"}, http.StatusForbidden, permsrv.ReasonProtectedGroup},
+		{"unlinking an admin's identity", "DELETE", "/v1/identities/slack/U1", "",
+			map[string]string{"Authorization": "Bearer " + testToken, httpUserHeader: "2"}, http.StatusForbidden, permsrv.ReasonProtectedGroup},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		for key, value := range test.headers {
+			r.Header.Set(key, value)
+		}
+
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, r)
+
+		var failure struct{ Id string }
+		json.NewDecoder(w.Body).Decode(&failure)
+		if w.Code != test.status || test.reason != "" && failure.Id != test.reason {
+			t.Errorf("%s: got %d %q, want %d %q", test.name, w.Code, failure.Id, test.status, test.reason)
+		}
+	}
+
+	allowed := &permsrv.PerformResponse{}
+	if err = h.Perform(context.Background(), &permsrv.PermissionsRequest{User: "2", PermissionsList: []string{"server_admins"}}, allowed); err != nil || allowed.CanPerform {
+		t.Fatalf("a helper made themselves an admin: %v, %v", allowed, err)
+	}
+
+	audit := &permsrv.AuditResponse{}
+	if err = h.ListAuditEntries(context.Background(), &permsrv.AuditRequest{}, audit); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(audit.Entries) != 1 || audit.Entries[0].Action != "AddPermission" || audit.Entries[0].Actor != "discord:2" {
+		t.Errorf("got audit entries %v, want the helper adding fleet", audit.Entries)
+	}
+}
@@ -0,0 +1,479 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	"github.com/micro/go-micro/errors"
+	"golang.org/x/net/context"
+)
+
+// Chat platforms (and EVE itself) a person can be known by.
+const (
+	platformDiscord = "discord"
+	platformSlack   = "slack"
+	platformEve     = "eve"
+)
+
+var platforms = map[string]bool{
+	platformDiscord: true,
+	platformSlack:   true,
+	platformEve:     true,
+}
+
+var discordMention = regexp.MustCompile(`^<@!?(\d+)>$`)
+
+// parseIdentity turns the user strings the bots hand us into a (platform, id)
+// pair. We accept "platform:id", Discord mentions and, for backwards
+// compatibility, bare ids which have always been Discord user ids.
+func parseIdentity(user string) (*permsrv.Identity, error) {
+	user = strings.TrimSpace(user)
+
+	if match := discordMention.FindStringSubmatch(user); match != nil {
+		return &permsrv.Identity{Platform: platformDiscord, Id: match[1]}, nil
+	}
+
+	if i := strings.Index(user, ":"); i >= 0 {
+		return checkIdentity(&permsrv.Identity{Platform: user[:i], Id: user[i+1:]})
+	}
+
+	return checkIdentity(&permsrv.Identity{Platform: platformDiscord, Id: user})
+}
+
+func checkIdentity(identity *permsrv.Identity) (*permsrv.Identity, error) {
+	if identity == nil || identity.Id == "" {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidUser, "No user given.")
+	}
+
+	platform := strings.ToLower(identity.Platform)
+	if platform == "" {
+		platform = platformDiscord
+	}
+
+	if !platforms[platform] {
+		return nil, errors.BadRequest(permsrv.ReasonInvalidUser, "Unknown platform `%s`.", identity.Platform)
+	}
+
+	return &permsrv.Identity{Platform: platform, Id: identity.Id}, nil
+}
+
+func identityString(identity *permsrv.Identity) string {
+	return fmt.Sprintf("%s:%s", identity.Platform, identity.Id)
+}
+
+// userString is how the bots write a user: Discord users as bare ids, like
+// they always were, everyone else as platform:id. parseIdentity reads it back.
+func userString(identity *permsrv.Identity) string {
+	if identity.Platform == platformDiscord {
+		return identity.Id
+	}
+
+	return identityString(identity)
+}
+
+// memberNames turns stored members back into users the bots understand.
+// Members stored under a principal are shown by their identity on platform,
+// or their first one if they have none there. Someone stored under more than
+// one of their identities is only listed once.
+func (h *permissionsHandler) memberNames(members []string, platform string) ([]string, error) {
+	if platform = strings.ToLower(platform); platform == "" {
+		platform = platformDiscord
+	}
+
+	// Only principals have identities linked to them, anything else is
+	// parsed as is.
+	pipe := h.Redis.Client.Pipeline()
+	linked := make([]*goredis.StringSliceCmd, len(members))
+	for member := range members {
+		if !strings.Contains(members[member], ":") && discordMention.FindString(members[member]) == "" {
+			linked[member] = pipe.SMembers(h.Redis.KeyName(fmt.Sprintf("principal:%s", members[member])))
+		}
+	}
+
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for member := range members {
+		identities := []string{members[member]}
+		if linked[member] != nil && len(linked[member].Val()) > 0 {
+			identities = linked[member].Val()
+			sort.Strings(identities)
+		}
+
+		var chosen *permsrv.Identity
+		for i := range identities {
+			identity, err := parseIdentity(identities[i])
+			if err != nil {
+				// Check reports these, listing them as stored is all we can do.
+				chosen = nil
+				break
+			}
+
+			if chosen == nil || identity.Platform == platform && chosen.Platform != platform {
+				chosen = identity
+			}
+		}
+
+		name := members[member]
+		if chosen != nil {
+			name = userString(chosen)
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// principalOf returns the principal an identity belongs to. Identities that
+// were never linked are their own principal.
+func (h *permissionsHandler) principalOf(identity *permsrv.Identity) (string, error) {
+	principal, err := h.Redis.Client.HGet(h.Redis.KeyName("identities"), identityString(identity)).Result()
+
+	if err == redis.Nil {
+		return identityString(identity), nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return principal, nil
+}
+
+// subjects returns every string a grant for this user may have been stored
+// under: their principal, all identities linked to it and the bare ids the
+// service stored before identities had platforms.
+func (h *permissionsHandler) subjects(user string) ([]string, error) {
+	subjects, err := h.subjectsOf([]string{user})
+	if err != nil {
+		return nil, err
+	}
+
+	return subjects[0], nil
+}
+
+// subjectsOf works out the subjects of many users in two pipelined round
+// trips, one to find their principals and one for the principals' identities.
+func (h *permissionsHandler) subjectsOf(users []string) ([][]string, error) {
+	identities := make([]*permsrv.Identity, len(users))
+	for user := range users {
+		identity, err := parseIdentity(users[user])
+		if err != nil {
+			return nil, err
+		}
+
+		identities[user] = identity
+	}
+
+	pipe := h.Redis.Client.Pipeline()
+	principalCmds := make([]*goredis.StringCmd, len(users))
+	for i := range identities {
+		principalCmds[i] = pipe.HGet(h.Redis.KeyName("identities"), identityString(identities[i]))
+	}
+
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	principals := make([]string, len(users))
+	linkedCmds := make([]*goredis.StringSliceCmd, len(users))
+	for i := range principalCmds {
+		principals[i] = identityString(identities[i])
+
+		if principalCmds[i].Err() == nil {
+			principals[i] = principalCmds[i].Val()
+			linkedCmds[i] = pipe.SMembers(h.Redis.KeyName(fmt.Sprintf("principal:%s", principals[i])))
+		}
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		return nil, err
+	}
+
+	subjects := make([][]string, len(users))
+	for i := range users {
+		linked := []string{identityString(identities[i])}
+		if linkedCmds[i] != nil && len(linkedCmds[i].Val()) > 0 {
+			linked = linkedCmds[i].Val()
+		}
+
+		subjects[i] = []string{principals[i]}
+		for _, l := range linked {
+			identity, err := parseIdentity(l)
+			if err != nil {
+				return nil, err
+			}
+
+			if s := identityString(identity); s != principals[i] {
+				subjects[i] = append(subjects[i], s)
+			}
+
+			if identity.Platform == platformDiscord {
+				subjects[i] = append(subjects[i], identity.Id)
+			}
+		}
+	}
+
+	return subjects, nil
+}
+
+func (h *permissionsHandler) principalIdentities(principal string) ([]*permsrv.Identity, error) {
+	members, err := h.Redis.Client.SMembers(h.Redis.KeyName(fmt.Sprintf("principal:%s", principal))).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var identities []*permsrv.Identity
+	for member := range members {
+		identity, err := parseIdentity(members[member])
+		if err != nil {
+			return nil, err
+		}
+
+		identities = append(identities, identity)
+	}
+
+	return identities, nil
+}
+
+// isMemberAny reports whether any of the subjects is in the permission group.
+func isMemberAny(c goredis.Cmdable, permName string, subjects []string) (bool, error) {
+	for subject := range subjects {
+		isMember, err := c.SIsMember(permName, subjects[subject]).Result()
+
+		if err != nil {
+			return false, err
+		}
+
+		if isMember {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func stringsToInterfaces(s []string) []interface{} {
+	i := make([]interface{}, len(s))
+	for n := range s {
+		i[n] = s[n]
+	}
+
+	return i
+}
+
+func newPrincipalId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func (h *permissionsHandler) LinkIdentity(ctx context.Context, request *permsrv.IdentityLink, response *permsrv.Principal) error {
+	h = h.withContext(ctx)
+
+	existing, err := checkIdentity(request.Existing)
+	if err != nil {
+		return err
+	}
+
+	identity, err := checkIdentity(request.Identity)
+	if err != nil {
+		return err
+	}
+
+	identities := h.Redis.KeyName("identities")
+	var principal string
+
+	err = h.watch(func(tx *goredis.Tx) error {
+		principal, err = tx.HGet(identities, identityString(existing)).Result()
+
+		if err != nil && err != redis.Nil {
+			return err
+		}
+
+		if err == redis.Nil {
+			if principal, err = newPrincipalId(); err != nil {
+				return err
+			}
+		}
+
+		principalKey := h.Redis.KeyName(fmt.Sprintf("principal:%s", principal))
+		if err = tx.Watch(principalKey).Err(); err != nil {
+			return err
+		}
+
+		current, err := tx.HGet(identities, identityString(identity)).Result()
+
+		if err != nil && err != redis.Nil {
+			return err
+		}
+
+		if err == nil && current != principal {
+			return errors.Conflict(permsrv.ReasonIdentityLinked, "Identity `%s` is already linked to someone else.", identityString(identity))
+		}
+
+		admin, err := h.touchesAdmin(tx, existing, identity)
+		if err != nil {
+			return err
+		}
+
+		if admin {
+			return errAdminIdentity
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			pipe.HSet(identities, identityString(existing), principal)
+			pipe.HSet(identities, identityString(identity), principal)
+			pipe.SAdd(principalKey, identityString(existing), identityString(identity))
+			return h.audit(pipe, "LinkIdentity", principal, identityString(existing), identityString(identity))
+		})
+
+		return err
+	}, identities, h.Redis.KeyName("members:server_admins"))
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Decisions are remembered per identity, not per principal.
+	h.forgetAll()
+	return h.fillPrincipal(principal, response)
+}
+
+func (h *permissionsHandler) UnlinkIdentity(ctx context.Context, request *permsrv.Identity, response *permsrv.Principal) error {
+	h = h.withContext(ctx)
+
+	identity, err := checkIdentity(request)
+	if err != nil {
+		return err
+	}
+
+	identities := h.Redis.KeyName("identities")
+	var principal string
+
+	err = h.watch(func(tx *goredis.Tx) error {
+		principal, err = tx.HGet(identities, identityString(identity)).Result()
+
+		if err == redis.Nil {
+			return errors.NotFound(permsrv.ReasonIdentityNotLinked, "Identity `%s` isn't linked to anyone.", identityString(identity))
+		}
+
+		if err != nil {
+			return err
+		}
+
+		principalKey := h.Redis.KeyName(fmt.Sprintf("principal:%s", principal))
+		if err = tx.Watch(principalKey).Err(); err != nil {
+			return err
+		}
+
+		admin, err := h.touchesAdmin(tx, identity)
+		if err != nil {
+			return err
+		}
+
+		if admin {
+			return errAdminIdentity
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			pipe.HDel(identities, identityString(identity))
+			pipe.SRem(principalKey, identityString(identity))
+			return h.audit(pipe, "UnlinkIdentity", principal, identityString(identity))
+		})
+
+		return err
+	}, identities, h.Redis.KeyName("members:server_admins"))
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	if err != nil {
+		return err
+	}
+
+	h.forgetAll()
+	return h.fillPrincipal(principal, response)
+}
+
+// touchesAdmin reports whether any of the identities, or anyone linked to
+// them, is in server_admins. Linking identities to an admin, or an admin's
+// identity to someone else, makes admins as surely as adding members does.
+func (h *permissionsHandler) touchesAdmin(tx *goredis.Tx, identities ...*permsrv.Identity) (bool, error) {
+	users := make([]string, len(identities))
+	for i := range identities {
+		users[i] = identityString(identities[i])
+	}
+
+	subjects, err := h.subjectsOf(users)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range subjects {
+		admin, err := isMemberAny(tx, h.Redis.KeyName("members:server_admins"), subjects[i])
+		if err != nil || admin {
+			return admin, err
+		}
+	}
+
+	return false, nil
+}
+
+func (h *permissionsHandler) GetPrincipal(ctx context.Context, request *permsrv.Identity, response *permsrv.Principal) error {
+	h = h.withContext(ctx)
+
+	identity, err := checkIdentity(request)
+	if err != nil {
+		return err
+	}
+
+	principal, err := h.principalOf(identity)
+	if err != nil {
+		return err
+	}
+
+	if err = h.fillPrincipal(principal, response); err != nil {
+		return err
+	}
+
+	if len(response.Identities) == 0 {
+		response.Identities = []*permsrv.Identity{identity}
+	}
+
+	return nil
+}
+
+func (h *permissionsHandler) fillPrincipal(principal string, response *permsrv.Principal) error {
+	identities, err := h.principalIdentities(principal)
+	if err != nil {
+		return err
+	}
+
+	response.Id = principal
+	response.Identities = identities
+	return nil
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"testing"
+
+	perms "github.com/chremoas/perms-srv/client"
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"golang.org/x/net/context"
+)
+
+func TestIdentityLinksCantChangeAdmins(t *testing.T) {
+	service, m := newTestService(t, "1")
+	ctx := context.Background()
+
+	// 2 is an admin through the identity linked to their principal.
+	m.HSet("perms:identities", "discord:2", "p2", "slack:U2", "p2")
+	m.SAdd("perms:principal:p2", "discord:2", "slack:U2")
+	m.SAdd("perms:members:server_admins", "slack:U2")
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"linking an identity to an admin", func() error {
+			_, err := service.LinkIdentity(ctx, &permsrv.IdentityLink{
+				Existing: &permsrv.Identity{Platform: "discord", Id: "1"},
+				Identity: &permsrv.Identity{Platform: "slack", Id: "EVIL"},
+			})
+			return err
+		}},
+		{"linking an admin to someone else", func() error {
+			_, err := service.LinkIdentity(ctx, &permsrv.IdentityLink{
+				Existing: &permsrv.Identity{Platform: "slack", Id: "EVIL"},
+				Identity: &permsrv.Identity{Platform: "discord", Id: "1"},
+			})
+			return err
+		}},
+		{"linking an identity to someone admin through a link", func() error {
+			_, err := service.LinkIdentity(ctx, &permsrv.IdentityLink{
+				Existing: &permsrv.Identity{Platform: "discord", Id: "2"},
+				Identity: &permsrv.Identity{Platform: "slack", Id: "EVIL"},
+			})
+			return err
+		}},
+		{"unlinking an admin's identity", func() error {
+			_, err := service.UnlinkIdentity(ctx, &permsrv.Identity{Platform: "discord", Id: "2"})
+			return err
+		}},
+	}
+
+	for _, test := range tests {
+		if reason := perms.Reason(test.call()); reason != permsrv.ReasonProtectedGroup {
+			t.Errorf("%s: got reason %q, want %q", test.name, reason, permsrv.ReasonProtectedGroup)
+		}
+	}
+
+	allowed, err := service.Perform(ctx, &permsrv.PermissionsRequest{User: "slack:EVIL", PermissionsList: []string{"anything"}})
+	if err != nil || allowed.CanPerform {
+		t.Fatalf("refused links made an admin: %v, %v", allowed, err)
+	}
+
+	// Everyone else's identities link as before.
+	if _, err = service.LinkIdentity(ctx, &permsrv.IdentityLink{
+		Existing: &permsrv.Identity{Platform: "discord", Id: "3"},
+		Identity: &permsrv.Identity{Platform: "slack", Id: "U3"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = service.UnlinkIdentity(ctx, &permsrv.Identity{Platform: "slack", Id: "U3"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/metadata"
+	"github.com/micro/go-micro/server"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/net/context"
+)
+
+// Logging is configured next to the redis settings:
+//
+//	perms:
+//	  log:
+//	    level: info           debug, info, warn or error
+//	    format: json          json or console
+//	    decisions:            Perform is called for every command, so its
+//	      first: 100          decisions are sampled: the first ones each
+//	      thereafter: 100     second, then one in thereafter
+//
+// Every change is logged at info, reads only at debug.
+const (
+	defaultDecisionsFirst      = 100
+	defaultDecisionsThereafter = 100
+)
+
+// mutations are the RPCs changing what we store. Dry runs and checks that
+// don't repair only read, see changes.
+var mutations = map[string]bool{
+	"AddPermission":         true,
+	"AddPermissionUser":     true,
+	"UpdatePermission":      true,
+	"RenamePermission":      true,
+	"RemovePermission":      true,
+	"RemovePermissionUser":  true,
+	"AddPermissionUsers":    true,
+	"RemovePermissionUsers": true,
+	"SetPermissionUsers":    true,
+	"LinkIdentity":          true,
+	"UnlinkIdentity":        true,
+	"RegisterPermissions":   true,
+	"Import":                true,
+	"Check":                 true,
+}
+
+// NewLogger builds the logger configured in perms.log.
+func NewLogger() (*zap.Logger, error) {
+	conf := zap.NewProductionConfig()
+	// We sample decisions ourselves, changes must all be logged.
+	conf.Sampling = nil
+
+	if level := viper.GetString("perms.log.level"); level != "" {
+		if err := conf.Level.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("Unknown log level `%s`, use debug, info, warn or error.", level)
+		}
+	}
+
+	switch format := strings.ToLower(viper.GetString("perms.log.format")); format {
+	case "", "json":
+	case "console":
+		conf.Encoding = "console"
+		conf.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	default:
+		return nil, fmt.Errorf("Unknown log format `%s`, use json or console.", format)
+	}
+
+	return conf.Build()
+}
+
+// LogWrapper logs every change made through the RPCs and a sample of the
+// Perform decisions, with who asked, about which groups, what came of it,
+// how long it took and the request id.
+func LogWrapper(logger *zap.Logger) server.HandlerWrapper {
+	first, thereafter := viper.GetInt("perms.log.decisions.first"), viper.GetInt("perms.log.decisions.thereafter")
+	if first <= 0 {
+		first = defaultDecisionsFirst
+	}
+
+	if thereafter <= 0 {
+		thereafter = defaultDecisionsThereafter
+	}
+
+	decisions := logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSampler(core, time.Second, first, thereafter)
+	}))
+
+	return func(fn server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, request server.Request, response interface{}) error {
+			start := time.Now()
+			err := fn(ctx, request, response)
+
+			fields := []zap.Field{
+				zap.String("method", request.Endpoint()),
+				zap.String("request_id", requestId(ctx)),
+				zap.Duration("latency", time.Since(start)),
+				zap.String("outcome", outcome(err)),
+			}
+
+			if err != nil {
+				fields = append(fields, zap.String("error", detail(err)))
+			}
+
+			// Who is behind a request made through the HTTP API.
+			if md, _ := metadata.FromContext(ctx); md[httpUserHeader] != "" {
+				fields = append(fields, zap.String("caller", md[httpUserHeader]))
+			}
+
+			method := request.Endpoint()[strings.LastIndex(request.Endpoint(), ".")+1:]
+			switch {
+			case method == "Perform" || method == "PerformBatch":
+				logDecisions(decisions, request.Body(), response, fields)
+			case !changes(method, request.Body()):
+				logger.Debug("Read", append(fields, requestFields(request.Body())...)...)
+			case err != nil:
+				logger.Warn("Change refused", append(fields, requestFields(request.Body())...)...)
+			default:
+				logger.Info("Changed", append(fields, requestFields(request.Body())...)...)
+			}
+
+			return err
+		}
+	}
+}
+
+// changes reports whether a request to method changes what we store.
+func changes(method string, body interface{}) bool {
+	switch r := body.(type) {
+	case *permsrv.CheckRequest:
+		return r.Repair
+	case *permsrv.ImportRequest:
+		return !r.DryRun
+	case *permsrv.SetPermissionUsersRequest:
+		return !r.DryRun
+	}
+
+	return mutations[method]
+}
+
+// requestId is the id the caller gave the request, or go-micro's.
+func requestId(ctx context.Context) string {
+	md, ok := metadata.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if id := md["X-Request-Id"]; id != "" {
+		return id
+	}
+
+	return md["Micro-Id"]
+}
+
+// logDecisions logs each decided request on its own.
+func logDecisions(logger *zap.Logger, body, response interface{}, fields []zap.Field) {
+	var requests []*permsrv.PermissionsRequest
+	var responses []*permsrv.PerformResponse
+
+	switch body := body.(type) {
+	case *permsrv.PermissionsRequest:
+		requests = []*permsrv.PermissionsRequest{body}
+		if response, ok := response.(*permsrv.PerformResponse); ok {
+			responses = []*permsrv.PerformResponse{response}
+		}
+	case *permsrv.PerformBatchRequest:
+		requests = body.Requests
+		if response, ok := response.(*permsrv.PerformBatchResponse); ok {
+			responses = response.Responses
+		}
+	}
+
+	for request := range requests {
+		result := "failed"
+		if request < len(responses) {
+			result = decisionDenied
+			if responses[request].CanPerform {
+				result = decisionAllowed
+			}
+		}
+
+		logger.Info("Decided", append(fields,
+			zap.String("user", requests[request].User),
+			zap.Strings("groups", requests[request].PermissionsList),
+			zap.String("result", result),
+		)...)
+	}
+}
+
+// requestFields says who and what a request is about.
+func requestFields(body interface{}) []zap.Field {
+	switch body := body.(type) {
+	case *permsrv.Permission:
+		return []zap.Field{zap.String("group", body.Name)}
+	case *permsrv.PermissionUser:
+		return []zap.Field{zap.String("user", body.User), zap.String("group", body.Permission)}
+	case *permsrv.RenamePermissionRequest:
+		return []zap.Field{zap.String("group", body.Name), zap.String("new_name", body.NewName)}
+	case *permsrv.BulkPermissionUsers:
+		return []zap.Field{zap.Strings("users", body.Users), zap.Strings("groups", body.Permissions), zap.Bool("atomic", body.Atomic)}
+	case *permsrv.SetPermissionUsersRequest:
+		return []zap.Field{zap.String("group", body.Permission), zap.Strings("users", body.Users), zap.Bool("dry_run", body.DryRun)}
+	case *permsrv.IdentityLink:
+		var fields []zap.Field
+		if body.Identity != nil {
+			fields = append(fields, zap.String("identity", identityString(body.Identity)))
+		}
+
+		if body.Existing != nil {
+			fields = append(fields, zap.String("existing", identityString(body.Existing)))
+		}
+
+		return fields
+	case *permsrv.Identity:
+		return []zap.Field{zap.String("identity", identityString(body))}
+	case *permsrv.PermissionsRegistration:
+		groups := make([]string, len(body.PermissionsList))
+		for group := range body.PermissionsList {
+			groups[group] = body.PermissionsList[group].Name
+		}
+
+		return []zap.Field{zap.String("service", body.Service), zap.Strings("groups", groups)}
+	case *permsrv.UsersRequest:
+		return []zap.Field{zap.String("group", body.Permission)}
+	case *permsrv.UserPermissionsRequest:
+		return []zap.Field{zap.String("user", body.User)}
+	case *permsrv.ImportRequest:
+		return []zap.Field{zap.String("mode", body.Mode), zap.Bool("dry_run", body.DryRun)}
+	case *permsrv.CheckRequest:
+		return []zap.Field{zap.Bool("repair", body.Repair)}
+	}
+
+	return nil
+}
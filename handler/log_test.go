@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"testing"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/metadata"
+	"github.com/micro/go-micro/server"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/net/context"
+)
+
+func TestLogLevels(t *testing.T) {
+	viper.Set("perms.log.decisions.first", 2)
+	viper.Set("perms.log.decisions.thereafter", 1000)
+	t.Cleanup(func() {
+		viper.Set("perms.log.decisions.first", 0)
+		viper.Set("perms.log.decisions.thereafter", 0)
+	})
+
+	h, _ := newTestHandler(t, "1")
+	core, logs := observer.New(zapcore.DebugLevel)
+	service := serve(t, h, []server.Option{server.WrapHandler(LogWrapper(zap.New(core)))}, nil)
+	ctx := metadata.NewContext(context.Background(), metadata.Metadata{httpUserHeader: "1", "X-Request-Id": "r1"})
+
+	service.AddPermission(ctx, &permsrv.Permission{Name: "fleet"})
+	service.AddPermission(ctx, &permsrv.Permission{Name: "fleet"})
+	service.ListPermissions(ctx, &permsrv.NilRequest{})
+	service.Import(ctx, &permsrv.ImportRequest{DryRun: true, Data: []byte(`{"version": 1}`)})
+	for i := 0; i < 5; i++ {
+		service.Perform(ctx, &permsrv.PermissionsRequest{User: "10", PermissionsList: []string{"fleet"}})
+	}
+
+	want := []struct {
+		level   zapcore.Level
+		message string
+		fields  map[string]interface{}
+	}{
+		{zapcore.InfoLevel, "Changed", map[string]interface{}{"method": "Permissions.AddPermission", "group": "fleet", "outcome": "ok"}},
+		{zapcore.WarnLevel, "Change refused", map[string]interface{}{"group": "fleet", "outcome": permsrv.ReasonGroupExists}},
+		{zapcore.DebugLevel, "Read", map[string]interface{}{"method": "Permissions.ListPermissions"}},
+		{zapcore.DebugLevel, "Read", map[string]interface{}{"method": "Permissions.Import", "dry_run": true}},
+		// Only the first decisions each second are logged.
+		{zapcore.InfoLevel, "Decided", map[string]interface{}{"user": "10", "result": decisionDenied}},
+		{zapcore.InfoLevel, "Decided", map[string]interface{}{"user": "10", "result": decisionDenied}},
+	}
+
+	entries := logs.AllUntimed()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %v", len(entries), len(want), entries)
+	}
+
+	for i := range want {
+		entry, fields := entries[i], entries[i].ContextMap()
+		if entry.Level != want[i].level || entry.Message != want[i].message {
+			t.Errorf("entry %d is %s %q, want %s %q", i, entry.Level, entry.Message, want[i].level, want[i].message)
+		}
+
+		if fields["caller"] != "1" || fields["request_id"] != "r1" {
+			t.Errorf("entry %d doesn't say who asked: %v", i, fields)
+		}
+
+		for key, value := range want[i].fields {
+			if fields[key] != value {
+				t.Errorf("entry %d has %s %v, want %v", i, key, fields[key], value)
+			}
+		}
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	t.Cleanup(func() {
+		viper.Set("perms.log.level", "")
+		viper.Set("perms.log.format", "")
+	})
+
+	for _, settings := range [][2]string{{"", ""}, {"debug", "console"}, {"WARN", "JSON"}} {
+		viper.Set("perms.log.level", settings[0])
+		viper.Set("perms.log.format", settings[1])
+		if _, err := NewLogger(); err != nil {
+			t.Errorf("%v: %s", settings, err)
+		}
+	}
+
+	viper.Set("perms.log.level", "warn")
+	if logger, _ := NewLogger(); logger.Core().Enabled(zapcore.InfoLevel) {
+		t.Error("logging info at level warn")
+	}
+
+	for _, settings := range [][2]string{{"loud", ""}, {"", "xml"}} {
+		viper.Set("perms.log.level", settings[0])
+		viper.Set("perms.log.format", settings[1])
+		if _, err := NewLogger(); err == nil {
+			t.Errorf("%v: built a logger", settings)
+		}
+	}
+}
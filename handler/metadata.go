@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	"github.com/micro/go-micro/errors"
+	"golang.org/x/net/context"
+)
+
+// Everything we know about a group besides its description lives in a hash
+// next to it:
+//
+//	meta:<group>  owner, creator, created, updated, tags (JSON), system and
+//	              revision
+//	revision      bumped with every change to any group
+//
+// Groups from before we kept metadata simply don't have one, until they are
+// changed for the first time.
+
+func (h *permissionsHandler) metaKey(name string) string {
+	return h.Redis.KeyName(fmt.Sprintf("meta:%s", name))
+}
+
+// created queues the metadata of a new group, stamping perm with the time.
+func (h *permissionsHandler) created(pipe goredis.Pipeliner, perm *permsrv.Permission) error {
+	tags, err := json.Marshal(perm.Tags)
+	if err != nil {
+		return err
+	}
+
+	perm.Created = time.Now().Unix()
+	perm.Updated = perm.Created
+
+	perm.Revision = 1
+
+	pipe.HMSet(h.metaKey(perm.Name), map[string]interface{}{
+		"owner":    perm.Owner,
+		"creator":  perm.Creator,
+		"created":  perm.Created,
+		"updated":  perm.Updated,
+		"tags":     string(tags),
+		"system":   strconv.FormatBool(perm.System),
+		"revision": perm.Revision,
+	})
+	pipe.Incr(h.Redis.KeyName("revision"))
+
+	return nil
+}
+
+// touch queues bumping the modification time and revision of the named
+// groups. Every change to a group has to touch it, the revisions are what
+// optimistic updates rely on.
+func (h *permissionsHandler) touch(pipe goredis.Pipeliner, names ...string) {
+	now := time.Now().Unix()
+	for name := range names {
+		pipe.HSet(h.metaKey(names[name]), "updated", now)
+		pipe.HIncrBy(h.metaKey(names[name]), "revision", 1)
+	}
+
+	pipe.Incr(h.Redis.KeyName("revision"))
+}
+
+// checkRevision fails if the caller expects the group to be at a revision
+// it's no longer at. Callers that don't expect anything (0) always pass. Use
+// it inside a transaction watching the group's metadata.
+func (h *permissionsHandler) checkRevision(c goredis.Cmdable, name string, expected int64) error {
+	if expected == 0 {
+		return nil
+	}
+
+	current, err := c.HGet(h.metaKey(name), "revision").Int64()
+
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	if current != expected {
+		return errors.Conflict(permsrv.ReasonRevisionMismatch,
+			"Permission group `%s` is at revision %d, not %d. Someone else changed it, please reload and try again.",
+			name, current, expected)
+	}
+
+	return nil
+}
+
+// revision returns the revision of all groups together.
+func (h *permissionsHandler) revision() (int64, error) {
+	revision, err := h.Redis.Client.Get(h.Redis.KeyName("revision")).Int64()
+
+	if err == redis.Nil {
+		return 0, nil
+	}
+
+	return revision, err
+}
+
+// fillMeta copies a metadata hash onto perm.
+func fillMeta(perm *permsrv.Permission, meta map[string]string) {
+	perm.Owner = meta["owner"]
+	perm.Creator = meta["creator"]
+	perm.Created, _ = strconv.ParseInt(meta["created"], 10, 64)
+	perm.Updated, _ = strconv.ParseInt(meta["updated"], 10, 64)
+	perm.System, _ = strconv.ParseBool(meta["system"])
+	perm.Revision, _ = strconv.ParseInt(meta["revision"], 10, 64)
+	_ = json.Unmarshal([]byte(meta["tags"]), &perm.Tags)
+
+	// server_admins is set up by chremoas-ctl, not through us.
+	if perm.Name == "server_admins" {
+		perm.System = true
+	}
+}
+
+// describe looks up the descriptions and metadata of the named groups. Groups
+// without a description are left out; they only have a members set left
+// behind.
+func (h *permissionsHandler) describe(names []string) ([]*permsrv.Permission, error) {
+	pipe := h.Redis.Client.Pipeline()
+	descriptions := make([]*goredis.StringCmd, len(names))
+	metas := make([]*goredis.StringStringMapCmd, len(names))
+	for name := range names {
+		descriptions[name] = pipe.Get(h.Redis.KeyName(fmt.Sprintf("description:%s", names[name])))
+		metas[name] = pipe.HGetAll(h.metaKey(names[name]))
+	}
+
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	var perms []*permsrv.Permission
+	for name := range names {
+		if descriptions[name].Err() != nil {
+			continue
+		}
+
+		perm := &permsrv.Permission{Name: names[name], Description: descriptions[name].Val()}
+		fillMeta(perm, metas[name].Val())
+		perms = append(perms, perm)
+	}
+
+	return perms, nil
+}
+
+func (h *permissionsHandler) GetPermission(ctx context.Context, request *permsrv.Permission, response *permsrv.Permission) error {
+	h = h.withContext(ctx)
+
+	perms, err := h.describe([]string{canonicalName(request.Name)})
+	if err != nil {
+		return err
+	}
+
+	if len(perms) == 0 {
+		return groupNotFound(canonicalName(request.Name))
+	}
+
+	*response = *perms[0]
+	return nil
+}
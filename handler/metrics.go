@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+)
+
+// Metrics go to the default Prometheus registry, served by the exporter
+// started in main.
+
+// How a Perform request was decided.
+const (
+	decisionAllowed = "allowed"
+	decisionDenied  = "denied"
+	decisionAdmin   = "admin"
+
+	decidedByStore  = "store"
+	decidedByCache  = "cache"
+	decidedByPolicy = "policy"
+
+	// Callers can ask about any name, only groups that exist get a label of
+	// their own so the number of series stays bounded.
+	unknownPermission = "unknown"
+)
+
+var (
+	performDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "perms",
+		Name:      "perform_decisions_total",
+		Help:      "Perform decisions by requested permission (unknown for names that aren't groups), result (allowed, denied or admin) and what decided it (store, cache or policy).",
+	}, []string{"permission", "result", "source"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "perms",
+		Name:      "rpc_duration_seconds",
+		Help:      "How long RPCs took, by method and outcome (ok, a reason code or error).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "outcome"})
+
+	storeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "perms",
+		Name:      "store_duration_seconds",
+		Help:      "How long Redis commands and pipelines took, by command.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"command"})
+
+	storeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "perms",
+		Name:      "store_errors_total",
+		Help:      "Failed Redis commands and pipelines, by command.",
+	}, []string{"command"})
+
+	groupsDesc = prometheus.NewDesc("perms_groups", "Number of permission groups.", nil, nil)
+
+	membersDesc = prometheus.NewDesc("perms_members", "Number of members of a permission group.", []string{"permission"}, nil)
+
+	registerMetrics sync.Once
+)
+
+// instrument registers the metrics, the first time, and times the store
+// calls.
+func (h *permissionsHandler) instrument() {
+	registerMetrics.Do(func() {
+		prometheus.MustRegister(performDecisions, rpcDuration, storeDuration, storeErrors, &groupCollector{Redis: h.Redis})
+	})
+
+	h.Redis.Client.WrapProcess(timeProcess)
+	h.Redis.Client.WrapProcessPipeline(timePipeline)
+}
+
+func timeProcess(process func(cmd goredis.Cmder) error) func(cmd goredis.Cmder) error {
+	return func(cmd goredis.Cmder) error {
+		start := time.Now()
+		err := process(cmd)
+		observeStore(cmd.Name(), start, err)
+		return err
+	}
+}
+
+func timePipeline(process func(cmds []goredis.Cmder) error) func(cmds []goredis.Cmder) error {
+	return func(cmds []goredis.Cmder) error {
+		start := time.Now()
+		err := process(cmds)
+		observeStore("pipeline", start, err)
+		return err
+	}
+}
+
+func observeStore(command string, start time.Time, err error) {
+	storeDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+
+	// Missing keys are answers, not failures.
+	if err != nil && err != redis.Nil {
+		storeErrors.WithLabelValues(command).Inc()
+	}
+}
+
+// countDecisions records how the requests were decided.
+func (h *permissionsHandler) countDecisions(requests []*permsrv.PermissionsRequest, canPerform, admin []bool, source string) {
+	for request := range requests {
+		result := decisionDenied
+		switch {
+		case admin != nil && admin[request]:
+			result = decisionAdmin
+		case canPerform[request]:
+			result = decisionAllowed
+		}
+
+		for _, perm := range requests[request].PermissionsList {
+			if _, known := h.groups.Load(perm); !known {
+				perm = unknownPermission
+			}
+
+			performDecisions.WithLabelValues(perm, result, source).Inc()
+		}
+	}
+}
+
+// MetricsWrapper times every RPC.
+func MetricsWrapper(fn server.HandlerFunc) server.HandlerFunc {
+	return func(ctx context.Context, request server.Request, response interface{}) error {
+		start := time.Now()
+		err := fn(ctx, request, response)
+
+		rpcDuration.WithLabelValues(request.Endpoint(), outcome(err)).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// outcome is "ok", the reason code of refused requests or "error".
+func outcome(err error) string {
+	if err == nil {
+		return "ok"
+	}
+
+	if e, ok := err.(*errors.Error); ok && e.Id != "" {
+		return e.Id
+	}
+
+	return "error"
+}
+
+// groupCollector counts groups and their members when scraped, so the
+// numbers are right whoever changed them.
+type groupCollector struct {
+	Redis *redis.Client
+}
+
+func (c *groupCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- groupsDesc
+	descs <- membersDesc
+}
+
+func (c *groupCollector) Collect(metrics chan<- prometheus.Metric) {
+	h := &permissionsHandler{Redis: c.Redis}
+
+	names, err := h.keyNames("description:")
+	if err != nil {
+		return
+	}
+
+	pipe := h.Redis.Client.Pipeline()
+	counts := make([]*goredis.IntCmd, len(names))
+	for name := range names {
+		counts[name] = pipe.SCard(h.Redis.KeyName(fmt.Sprintf("members:%s", names[name])))
+	}
+
+	if _, err = pipe.Exec(); err != nil {
+		return
+	}
+
+	metrics <- prometheus.MustNewConstMetric(groupsDesc, prometheus.GaugeValue, float64(len(names)))
+	for name := range names {
+		metrics <- prometheus.MustNewConstMetric(membersDesc, prometheus.GaugeValue, float64(counts[name].Val()), names[name])
+	}
+}
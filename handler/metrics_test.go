@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/context"
+)
+
+// observations is how many times a histogram series was observed.
+func observations(t *testing.T, histogram prometheus.Observer) uint64 {
+	metric := &dto.Metric{}
+	if err := histogram.(prometheus.Metric).Write(metric); err != nil {
+		t.Fatal(err)
+	}
+
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestDecisionMetrics(t *testing.T) {
+	h, m := newOutageHandler(t)
+
+	// The metrics are shared by every test, only what this one adds counts.
+	series := [][3]string{
+		{"fleet", decisionAllowed, decidedByStore},
+		{"fleet", decisionDenied, decidedByStore},
+		{unknownPermission, decisionDenied, decidedByStore},
+		{unknownPermission, decisionAdmin, decidedByStore},
+		{"fleet", decisionAllowed, decidedByCache},
+		{"fleet", decisionDenied, decidedByPolicy},
+	}
+
+	before := make([]float64, len(series))
+	for i := range series {
+		before[i] = testutil.ToFloat64(performDecisions.WithLabelValues(series[i][:]...))
+	}
+
+	canPerform(t, h, "10", "fleet")
+	canPerform(t, h, "20", "fleet")
+	canPerform(t, h, "20", "nothing")
+	canPerform(t, h, "1", "nothing")
+
+	m.Close()
+	canPerform(t, h, "10", "fleet")
+	canPerform(t, h, "30", "fleet")
+
+	for i := range series {
+		if got := testutil.ToFloat64(performDecisions.WithLabelValues(series[i][:]...)) - before[i]; got != 1 {
+			t.Errorf("%v counted %v times, want once", series[i], got)
+		}
+	}
+}
+
+func TestRPCMetrics(t *testing.T) {
+	h, _ := newTestHandler(t, "1")
+	service := serve(t, h, []server.Option{server.WrapHandler(MetricsWrapper)}, nil)
+	ctx := context.Background()
+
+	ok := rpcDuration.WithLabelValues("Permissions.AddPermission", "ok")
+	refused := rpcDuration.WithLabelValues("Permissions.AddPermission", permsrv.ReasonGroupExists)
+	okBefore, refusedBefore := observations(t, ok), observations(t, refused)
+
+	service.AddPermission(ctx, &permsrv.Permission{Name: "fleet"})
+	service.AddPermission(ctx, &permsrv.Permission{Name: "fleet"})
+
+	if got := observations(t, ok) - okBefore; got != 1 {
+		t.Errorf("timed %d successful calls, want 1", got)
+	}
+
+	if got := observations(t, refused) - refusedBefore; got != 1 {
+		t.Errorf("timed %d refused calls, want 1", got)
+	}
+}
+
+func TestStoreMetrics(t *testing.T) {
+	h, m := newTestHandler(t)
+	h.instrument()
+
+	get := storeDuration.WithLabelValues("get")
+	timed, failed := observations(t, get), testutil.ToFloat64(storeErrors.WithLabelValues("get"))
+
+	h.Redis.Client.Get(h.Redis.KeyName("nothing"))
+	if got := observations(t, get) - timed; got != 1 {
+		t.Errorf("timed %d GETs, want 1", got)
+	}
+
+	if got := testutil.ToFloat64(storeErrors.WithLabelValues("get")) - failed; got != 0 {
+		t.Errorf("a missing key counted as %v errors", got)
+	}
+
+	m.Close()
+	h.Redis.Client.Get(h.Redis.KeyName("nothing"))
+	if got := testutil.ToFloat64(storeErrors.WithLabelValues("get")) - failed; got != 1 {
+		t.Errorf("a GET without Redis counted as %v errors, want 1", got)
+	}
+}
+
+func TestGroupMetrics(t *testing.T) {
+	h, m := newTestHandler(t, "1", "2")
+	m.Set(h.Redis.KeyName("description:fleet"), "Fleet commanders")
+	m.SAdd(h.Redis.KeyName("members:fleet"), "discord:10")
+
+	want := `
+# HELP perms_groups Number of permission groups.
+# TYPE perms_groups gauge
+perms_groups 2
+# HELP perms_members Number of members of a permission group.
+# TYPE perms_members gauge
+perms_members{permission="fleet"} 1
+perms_members{permission="server_admins"} 2
+`
+
+	if err := testutil.CollectAndCompare(&groupCollector{Redis: h.Redis}, strings.NewReader(want), "perms_groups", "perms_members"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,326 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	redis "github.com/chremoas/services-common/redis"
+)
+
+// Before identities, metadata and registrations all we stored were
+//
+//	description:<group>  the group's description
+//	members:<group>      whatever string the bot handed us for each member
+//
+// and RemovePermission left the members set behind. Migrate brings such data
+// up to date: members become platform:id identities, groups get metadata and
+// revisions, and it reports what it couldn't make sense of.
+
+// MigrateOptions says where and how to migrate.
+type MigrateOptions struct {
+	// Target receives the migrated groups. Without one the data is migrated
+	// in place.
+	Target *redis.Client
+	// DryRun only reports what would change.
+	DryRun bool
+	// DropOrphans removes the members sets of groups that don't exist
+	// anymore. It only applies in place.
+	DropOrphans bool
+}
+
+// MigrationReport is what Migrate found and did. Pairs are "group member".
+type MigrationReport struct {
+	Groups  int
+	Members int
+	// Orphans are groups that only have a members set left.
+	Orphans []string
+	// Mentions and BareIds are members stored as Discord mentions or bare
+	// Discord ids rather than identities.
+	Mentions []string
+	BareIds  []string
+	// Invalid are groups and members that can't be migrated, with the reason.
+	Invalid []string
+	// Changes are the changes made, or that would be made for dry runs.
+	Changes []string
+	// Problems are the differences the verification found after migrating.
+	Problems []string
+}
+
+// Migrate reads the groups stored in source, whatever layout they are in,
+// and writes them to the target in the current one, verifying the result.
+func Migrate(source *redis.Client, options MigrateOptions) (*MigrationReport, error) {
+	if options.Target == nil {
+		return migrateStore(source, options)
+	}
+
+	target := &permissionsHandler{Redis: options.Target}
+
+	version, err := target.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	if version > schemaVersion {
+		return nil, newerSchemaError(version)
+	}
+
+	report, err := migrateStore(source, options)
+	if err != nil || options.DryRun || len(report.Problems) > 0 {
+		return report, err
+	}
+
+	// The target is in the current layout now, the service mustn't migrate
+	// it again.
+	return report, target.Redis.Client.Set(target.Redis.KeyName("schema"), schemaVersion, 0).Err()
+}
+
+// migrateStore is Migrate without keeping track of the target's schema, which the
+// startup migrations do themselves.
+func migrateStore(source *redis.Client, options MigrateOptions) (*MigrationReport, error) {
+	from := &permissionsHandler{Redis: source}
+	to := from
+	if options.Target != nil {
+		to = &permissionsHandler{Redis: options.Target}
+	}
+
+	report := &MigrationReport{}
+
+	current, err := from.dump()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted, legacy := report.convert(current)
+
+	if err = from.findOrphans(report, current); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(wanted)
+	if err != nil {
+		return nil, err
+	}
+
+	// Migrating converts the admins' members too.
+	imported := &permsrv.ImportResponse{}
+	err = to.importDump(&permsrv.ImportRequest{Data: data, DryRun: options.DryRun}, imported, true)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Changes = imported.Changes
+
+	// Copying elsewhere leaves the source alone, in place the old forms of
+	// the members go once their identities are there.
+	if options.Target == nil {
+		if err = from.dropLegacy(report, legacy, options); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.DryRun {
+		return report, nil
+	}
+
+	return report, to.verify(report, wanted, legacy, options.Target == nil)
+}
+
+// convert turns a dump of the source into what the target should hold,
+// noting legacy members along the way. It returns the dump and the legacy
+// members of each group.
+func (report *MigrationReport) convert(current *permsrv.Dump) (*permsrv.Dump, map[string][]string) {
+	principals := make(map[string]bool)
+	for principal := range current.Identities {
+		principals[principal] = true
+	}
+
+	wanted := &permsrv.Dump{Version: permsrv.DumpVersion, Identities: current.Identities}
+	legacy := make(map[string][]string)
+
+	for _, group := range current.Groups {
+		if group.Name != "server_admins" {
+			if name, err := checkName(group.Name); err != nil || name != group.Name {
+				report.Invalid = append(report.Invalid, fmt.Sprintf("group %s: %s", group.Name, nameProblem(group.Name, err)))
+				continue
+			}
+		}
+
+		report.Groups++
+
+		migrated := *group
+		migrated.Members = nil
+		seen := make(map[string]bool)
+
+		for _, member := range group.Members {
+			report.Members++
+
+			subject := member
+			if !principals[member] {
+				identity, err := parseIdentity(member)
+				if err != nil {
+					report.Invalid = append(report.Invalid, fmt.Sprintf("member %s %s: %s", group.Name, member, detail(err)))
+					continue
+				}
+
+				subject = identityString(identity)
+			}
+
+			switch {
+			case subject == member:
+			case discordMention.MatchString(strings.TrimSpace(member)):
+				report.Mentions = append(report.Mentions, fmt.Sprintf("%s %s", group.Name, member))
+			case !strings.Contains(member, ":"):
+				report.BareIds = append(report.BareIds, fmt.Sprintf("%s %s", group.Name, member))
+			}
+
+			if subject != member {
+				legacy[group.Name] = append(legacy[group.Name], member)
+			}
+
+			if !seen[subject] {
+				seen[subject] = true
+				migrated.Members = append(migrated.Members, subject)
+			}
+		}
+
+		sort.Strings(migrated.Members)
+		wanted.Groups = append(wanted.Groups, &migrated)
+	}
+
+	return wanted, legacy
+}
+
+func nameProblem(name string, err error) string {
+	if err != nil {
+		return detail(err)
+	}
+
+	return fmt.Sprintf("not lower case, starting the service renames it to `%s`", canonicalName(name))
+}
+
+// findOrphans reports the members sets of groups without a description.
+func (h *permissionsHandler) findOrphans(report *MigrationReport, current *permsrv.Dump) error {
+	described := make(map[string]bool)
+	for _, group := range current.Groups {
+		described[group.Name] = true
+	}
+
+	keys, err := h.Redis.Client.Keys(h.Redis.KeyName("members:*")).Result()
+
+	if err != nil {
+		return err
+	}
+
+	for key := range keys {
+		name := strings.TrimPrefix(keys[key], h.Redis.KeyName("members:"))
+		if !described[name] {
+			report.Orphans = append(report.Orphans, name)
+		}
+	}
+
+	sort.Strings(report.Orphans)
+	return nil
+}
+
+// dropLegacy removes the members that were stored in a legacy form, and the
+// orphans if asked to, from a database migrated in place.
+func (h *permissionsHandler) dropLegacy(report *MigrationReport, legacy map[string][]string, options MigrateOptions) error {
+	var changes []string
+	for _, name := range sortedNames(legacy) {
+		for _, member := range legacy[name] {
+			changes = append(changes, fmt.Sprintf("-member %s %s", name, member))
+		}
+	}
+
+	if options.DropOrphans {
+		for _, orphan := range report.Orphans {
+			changes = append(changes, fmt.Sprintf("-orphan %s", orphan))
+		}
+	}
+
+	report.Changes = append(report.Changes, changes...)
+
+	if options.DryRun || len(changes) == 0 {
+		return nil
+	}
+
+	pipe := h.Redis.Client.TxPipeline()
+	for _, name := range sortedNames(legacy) {
+		pipe.SRem(h.Redis.KeyName(fmt.Sprintf("members:%s", name)), stringsToInterfaces(legacy[name])...)
+		h.touch(pipe, name)
+	}
+
+	if options.DropOrphans {
+		for _, orphan := range report.Orphans {
+			pipe.Del(h.Redis.KeyName(fmt.Sprintf("members:%s", orphan)))
+		}
+	}
+
+	if err := h.audit(pipe, "Migrate", changes...); err != nil {
+		return err
+	}
+
+	_, err := pipe.Exec()
+	return err
+}
+
+// verify checks the target holds every wanted group with its description
+// and members, and none of the legacy members if migrated in place.
+func (h *permissionsHandler) verify(report *MigrationReport, wanted *permsrv.Dump, legacy map[string][]string, inPlace bool) error {
+	migrated, err := h.dump()
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string]*permsrv.DumpGroup)
+	for _, group := range migrated.Groups {
+		groups[group.Name] = group
+	}
+
+	for _, group := range wanted.Groups {
+		got := groups[group.Name]
+		if got == nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("group %s is missing", group.Name))
+			continue
+		}
+
+		if got.Description != group.Description {
+			report.Problems = append(report.Problems, fmt.Sprintf("group %s has description %q, not %q", group.Name, got.Description, group.Description))
+		}
+
+		missing, _ := diffStrings(got.Members, group.Members)
+		for _, member := range missing {
+			report.Problems = append(report.Problems, fmt.Sprintf("member %s %s is missing", group.Name, member))
+		}
+
+		if !inPlace {
+			continue
+		}
+
+		present := make(map[string]bool)
+		for _, member := range got.Members {
+			present[member] = true
+		}
+
+		for _, member := range legacy[group.Name] {
+			if present[member] {
+				report.Problems = append(report.Problems, fmt.Sprintf("member %s %s is still there", group.Name, member))
+			}
+		}
+	}
+
+	return nil
+}
+
+func sortedNames(m map[string][]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+)
+
+// newLegacyStore returns a store in the layout from before identities, with
+// a mention, bare ids, a member and a group that can't be migrated and the
+// members set of a removed group.
+func newLegacyStore(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	m, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(m.Close)
+
+	m.Set("perms:description:server_admins", "Server Admins")
+	m.SAdd("perms:members:server_admins", "1")
+	m.Set("perms:description:fleet", "Fleet commanders")
+	m.SAdd("perms:members:fleet", "<@10>", "20", "discord:30", "irc:bob")
+	m.Set("perms:description:Ships", "Ship fitters")
+	m.SAdd("perms:members:removed", "40")
+
+	return &redis.Client{Client: goredis.NewClient(&goredis.Options{Addr: m.Addr()}), Prefix: "perms"}, m
+}
+
+func members(t *testing.T, m *miniredis.Miniredis, group string) []string {
+	members, err := m.Members("perms:members:" + group)
+	if err != nil {
+		t.Fatalf("%s: %s", group, err)
+	}
+
+	return members
+}
+
+func TestMigrateInPlace(t *testing.T) {
+	source, m := newLegacyStore(t)
+
+	report, err := Migrate(source, MigrateOptions{DryRun: true, DropOrphans: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Groups != 2 || report.Members != 5 {
+		t.Errorf("counted %d groups and %d members, want 2 and 5", report.Groups, report.Members)
+	}
+
+	// The groups come in no particular order.
+	sort.Strings(report.BareIds)
+	sort.Strings(report.Invalid)
+
+	found := [][]string{report.Orphans, report.Mentions, report.BareIds, report.Invalid}
+	want := [][]string{
+		{"removed"},
+		{"fleet <@10>"},
+		{"fleet 20", "server_admins 1"},
+		{"group Ships: not lower case, starting the service renames it to `ships`", "member fleet irc:bob: Unknown platform `irc`."},
+	}
+
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("found orphans, mentions, bare ids and invalid\n %q\nwant\n %q", found, want)
+	}
+
+	if got := members(t, m, "fleet"); !reflect.DeepEqual(got, []string{"20", "<@10>", "discord:30", "irc:bob"}) {
+		t.Errorf("a dry run changed fleet to %v", got)
+	}
+
+	if report, err = Migrate(source, MigrateOptions{DropOrphans: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Problems) > 0 {
+		t.Errorf("the migration verified with %v", report.Problems)
+	}
+
+	// What couldn't be migrated is left for people to deal with.
+	if got := members(t, m, "fleet"); !reflect.DeepEqual(got, []string{"discord:10", "discord:20", "discord:30", "irc:bob"}) {
+		t.Errorf("fleet has %v", got)
+	}
+
+	if got := members(t, m, "server_admins"); !reflect.DeepEqual(got, []string{"discord:1"}) {
+		t.Errorf("server_admins has %v", got)
+	}
+
+	if m.Exists("perms:members:removed") {
+		t.Error("the orphaned members set wasn't dropped")
+	}
+
+	if !m.Exists("perms:meta:fleet") {
+		t.Error("fleet got no metadata")
+	}
+
+	// Migrating again finds nothing left to do.
+	if report, err = Migrate(source, MigrateOptions{}); err != nil || len(report.Mentions)+len(report.BareIds)+len(report.Orphans) > 0 {
+		t.Errorf("migrating again got %+v, %v", report, err)
+	}
+}
+
+func TestMigrateToAnotherStore(t *testing.T) {
+	source, m := newLegacyStore(t)
+	target, tm := newLegacyStore(t)
+	tm.FlushAll()
+
+	report, err := Migrate(source, MigrateOptions{Target: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Problems) > 0 {
+		t.Errorf("the migration verified with %v", report.Problems)
+	}
+
+	if got := members(t, tm, "fleet"); !reflect.DeepEqual(got, []string{"discord:10", "discord:20", "discord:30"}) {
+		t.Errorf("fleet has %v in the target", got)
+	}
+
+	if got := members(t, m, "fleet"); !reflect.DeepEqual(got, []string{"20", "<@10>", "discord:30", "irc:bob"}) {
+		t.Errorf("fleet changed to %v in the source", got)
+	}
+
+	if schema, _ := tm.Get("perms:schema"); schema != "2" {
+		t.Errorf("the target is at schema %q, want 2", schema)
+	}
+
+	// A target a newer perms-srv wrote isn't ours to touch.
+	tm.Set("perms:schema", "99")
+	if _, err = Migrate(source, MigrateOptions{Target: target}); err != newerSchemaError(99) {
+		t.Errorf("migrating to a newer schema got %v", err)
+	}
+}
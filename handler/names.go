@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Group names end up inside Redis keys and KEYS patterns, so we keep them to
+// lower case letters, digits and a little punctuation. Anything with `:`, `*`
+// or whitespace would break the key layout or the scans.
+const maxNameLength = 64
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// Names nobody gets to create, they are managed outside the API.
+var reservedNames = map[string]bool{
+	"server_admins": true,
+}
+
+// invalidNameError is returned when a permission group can't be given a name.
+type invalidNameError struct {
+	Name   string
+	Reason string
+}
+
+func (e *invalidNameError) Error() string {
+	return fmt.Sprintf("Invalid permission group name `%s`: %s.", e.Name, e.Reason)
+}
+
+// canonicalName is the form group names are stored and looked up in. Names
+// are case insensitive, "Fleet_Commanders" is the same group as
+// "fleet_commanders".
+func canonicalName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func canonicalNames(names []string) {
+	for name := range names {
+		names[name] = canonicalName(names[name])
+	}
+}
+
+// checkName canonicalizes the name of a group about to be created (or renamed)
+// and makes sure it's one we can store.
+func checkName(name string) (string, error) {
+	canonical := canonicalName(name)
+
+	switch {
+	case canonical == "":
+		return "", &invalidNameError{Name: name, Reason: "the name is empty"}
+	case len(canonical) > maxNameLength:
+		return "", &invalidNameError{Name: name, Reason: fmt.Sprintf("names can't be longer than %d characters", maxNameLength)}
+	case reservedNames[canonical]:
+		return "", &invalidNameError{Name: name, Reason: "the name is reserved"}
+	case !validName.MatchString(canonical):
+		return "", &invalidNameError{Name: name, Reason: "names may only contain letters, digits, `_`, `-` and `.` and must start with a letter or digit"}
+	}
+
+	return canonical, nil
+}
+
+// canonicalizeNames renames groups created before names were canonicalized,
+// so lookups find them again. Groups whose names still aren't valid, or whose
+// canonical name is taken, are left alone and reported; an admin has to
+// rename them.
+func (h *permissionsHandler) canonicalizeNames() error {
+	prefix := h.Redis.KeyName("description:")
+
+	keys, err := h.Redis.Client.Keys(prefix + "*").Result()
+
+	if err != nil {
+		return err
+	}
+
+	for key := range keys {
+		name := strings.TrimPrefix(keys[key], prefix)
+		canonical := canonicalName(name)
+
+		if canonical != name {
+			if err = h.rename(name, canonical, 0); err != nil {
+				h.log.Error("Couldn't rename permission group", zap.String("group", name), zap.String("new_name", canonical), zap.Error(err))
+				continue
+			}
+		}
+
+		if _, err = checkName(canonical); err != nil && !reservedNames[canonical] {
+			h.log.Warn(detail(err)+" Rename it with RenamePermission.", zap.String("group", canonical))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// openAPI describes the HTTP API as an OpenAPI 3 document. The schemas are
+// generated from the proto messages the routes take and return, so it can't
+// drift from the RPCs.
+func openAPI() map[string]interface{} {
+	schemas := map[string]interface{}{
+		"Error": object(map[string]interface{}{
+			"id":     map[string]interface{}{"type": "string", "description": "The reason, one of the perms.* codes for refused requests."},
+			"code":   map[string]interface{}{"type": "integer", "format": "int32"},
+			"detail": map[string]interface{}{"type": "string"},
+			"status": map[string]interface{}{"type": "string"},
+		}),
+	}
+
+	paths := make(map[string]map[string]interface{})
+	for _, route := range routes {
+		if route.endpoint == "" {
+			continue
+		}
+
+		if paths[route.path] == nil {
+			paths[route.path] = make(map[string]interface{})
+		}
+
+		paths[route.path][strings.ToLower(route.method)] = operation(route, schemas)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "perms-srv",
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"token": map[string]interface{}{"type": "http", "scheme": "bearer"},
+				"session": map[string]interface{}{
+					"type": "apiKey", "in": "cookie", "name": sessionCookie,
+					"description": "The web UI's session, sent along with the " + uiHeader + " header.",
+				},
+			},
+		},
+	}
+}
+
+func operation(route route, schemas map[string]interface{}) map[string]interface{} {
+	requestType, responseType := endpointTypes(route.endpoint)
+
+	var parameters []interface{}
+	pathParams := make(map[string]bool)
+	for _, part := range strings.Split(route.path, "/") {
+		if strings.HasPrefix(part, "{") {
+			name := strings.Trim(part, "{}")
+			pathParams[name] = true
+			parameters = append(parameters, map[string]interface{}{
+				"name": name, "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+
+	op := map[string]interface{}{
+		"operationId": route.endpoint,
+		"responses": map[string]interface{}{
+			"200":     jsonContent("The "+responseType.Elem().Name()+".", schemaRef(responseType, schemas)),
+			"default": jsonContent("Why the request failed.", map[string]interface{}{"$ref": "#/components/schemas/Error"}),
+		},
+	}
+
+	switch route.method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		op["requestBody"] = map[string]interface{}{
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemaRef(requestType, schemas)},
+			},
+		}
+	default:
+		for _, field := range queryFields(requestType) {
+			if !pathParams[field.path[0]] {
+				parameters = append(parameters, map[string]interface{}{
+					"name": field.param, "in": "query", "schema": scalarSchema(field.kind),
+				})
+			}
+		}
+	}
+
+	if route.access != public {
+		op["security"] = []interface{}{map[string]interface{}{"token": []string{}}, map[string]interface{}{"session": []string{}}}
+	}
+
+	if route.access == changer {
+		parameters = append(parameters, map[string]interface{}{
+			"name": httpUserHeader, "in": "header",
+			"description": "The user making the change, for callers with the token. Sessions act as the user they belong to.",
+			"schema":      map[string]interface{}{"type": "string"},
+		})
+	}
+
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+
+	return op
+}
+
+func jsonContent(description string, schema interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// schemaRef adds the schema of a message, and those it refers to, and
+// returns a reference to it.
+func schemaRef(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	message := t.Elem()
+	ref := map[string]interface{}{"$ref": "#/components/schemas/" + message.Name()}
+	if _, ok := schemas[message.Name()]; ok {
+		return ref
+	}
+
+	properties := make(map[string]interface{})
+	schemas[message.Name()] = object(properties)
+
+	for _, field := range protoFields(message) {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		properties[name] = fieldSchema(field.Type, schemas)
+	}
+
+	return ref
+}
+
+func fieldSchema(t reflect.Type, schemas map[string]interface{}) interface{} {
+	switch {
+	case t.Kind() == reflect.Ptr:
+		return schemaRef(t, schemas)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case t.Kind() == reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": fieldSchema(t.Elem(), schemas)}
+	}
+
+	return scalarSchema(t.Kind())
+}
+
+func scalarSchema(kind reflect.Kind) map[string]interface{} {
+	switch kind {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	}
+
+	return map[string]interface{}{"type": "string"}
+}
+
+func object(properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
@@ -0,0 +1,352 @@
+package handler
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/errors"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// When Redis is down every command a bot runs would fail. Perform retries a
+// few times and then decides from the decisions it made recently, or if it
+// never saw the request, from a policy per group. Configure it next to the
+// redis settings:
+//
+//	perms:
+//	  outage:
+//	    default: closed       deny (closed) or allow (open) what we can't tell
+//	    permissions:          the same, per group
+//	      some_group: open
+//	    cacheSize: 10000      decisions to remember
+//	    cacheAge: 1h          how old a remembered decision may be to be used
+const (
+	defaultCacheSize = 10000
+	defaultCacheAge  = time.Hour
+
+	performAttempts = 3
+	performBackoff  = 50 * time.Millisecond
+
+	// Once the store failed we don't make every request wait for it to fail
+	// again, only one in a while tries it.
+	outageRecheck = 5 * time.Second
+)
+
+// When the store isn't there at startup we keep trying, waiting longer after
+// every failure.
+const (
+	prepareBackoff    = time.Second
+	maxPrepareBackoff = time.Minute
+)
+
+// keepPreparing retries prepare until the store is there and migrated.
+func (h *permissionsHandler) keepPreparing() {
+	backoff := prepareBackoff
+	for {
+		time.Sleep(backoff)
+
+		err := h.prepare()
+		if err == nil {
+			h.log.Info("Ready")
+			return
+		}
+
+		h.log.Warn("Not ready", zap.Error(err), zap.Duration("retry_in", backoff))
+
+		if backoff *= 2; backoff > maxPrepareBackoff {
+			backoff = maxPrepareBackoff
+		}
+	}
+}
+
+// outagePolicy says what to decide when we can't ask the store and don't
+// remember the answer.
+type outagePolicy struct {
+	// failOpen is the default, permissions overrides it per group.
+	failOpen    bool
+	permissions map[string]bool
+}
+
+func loadOutagePolicy() (outagePolicy, error) {
+	policy := outagePolicy{permissions: make(map[string]bool)}
+
+	var err error
+	if policy.failOpen, err = failsOpen(viper.GetString("perms.outage.default")); err != nil {
+		return policy, err
+	}
+
+	for perm, setting := range viper.GetStringMapString("perms.outage.permissions") {
+		if policy.permissions[canonicalName(perm)], err = failsOpen(setting); err != nil {
+			return policy, err
+		}
+	}
+
+	return policy, nil
+}
+
+func failsOpen(setting string) (bool, error) {
+	switch strings.ToLower(setting) {
+	case "", "closed":
+		return false, nil
+	case "open":
+		return true, nil
+	}
+
+	return false, fmt.Errorf("Unknown outage policy `%s`, use open or closed.", setting)
+}
+
+// allows decides a request the way perform would if every group failing
+// open had everyone in it.
+func (p outagePolicy) allows(perms []string) bool {
+	for _, perm := range perms {
+		open, ok := p.permissions[perm]
+		if !ok {
+			open = p.failOpen
+		}
+
+		if open {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decisionCache remembers the most recently made decisions. A nil cache
+// remembers nothing.
+type decisionCache struct {
+	mu      sync.Mutex
+	size    int
+	maxAge  time.Duration
+	entries map[string]*list.Element
+	// order has the most recently made decisions at the front.
+	order *list.List
+	// generation goes up whenever decisions are forgotten, so ones made
+	// from what the store said before aren't remembered after.
+	generation uint64
+}
+
+type decision struct {
+	key     string
+	perms   []string
+	allowed bool
+	made    time.Time
+}
+
+func newDecisionCache(size int, maxAge time.Duration) *decisionCache {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+
+	if maxAge <= 0 {
+		maxAge = defaultCacheAge
+	}
+
+	return &decisionCache{size: size, maxAge: maxAge, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// decisionKey is the same for every way of naming the same identity, "<@1>"
+// and "discord:1" are one user.
+func decisionKey(request *permsrv.PermissionsRequest) (string, []string) {
+	user := request.User
+	if identity, err := parseIdentity(user); err == nil {
+		user = identityString(identity)
+	}
+
+	perms := append([]string(nil), request.PermissionsList...)
+	sort.Strings(perms)
+	return user + "\x00" + strings.Join(perms, "\x00"), perms
+}
+
+// current is the generation to pass to put for decisions made from now on.
+func (c *decisionCache) current() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// put remembers a decision made at generation, unless decisions were
+// forgotten since.
+func (c *decisionCache) put(request *permsrv.PermissionsRequest, allowed bool, generation uint64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	key, perms := decisionKey(request)
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+	}
+
+	c.entries[key] = c.order.PushFront(&decision{key: key, perms: perms, allowed: allowed, made: time.Now()})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*decision).key)
+	}
+}
+
+func (c *decisionCache) get(request *permsrv.PermissionsRequest) (allowed bool, ok bool) {
+	if c == nil {
+		return false, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, _ := decisionKey(request)
+	element, ok := c.entries[key]
+	if !ok || time.Since(element.Value.(*decision).made) > c.maxAge {
+		return false, false
+	}
+
+	return element.Value.(*decision).allowed, true
+}
+
+// forget drops the decisions about groups whose members changed. Admins
+// can do anything, so a change to server_admins drops every decision.
+func (c *decisionCache) forget(groups ...string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := make(map[string]bool)
+	for _, group := range groups {
+		changed[group] = true
+	}
+
+	c.generation++
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if d := element.Value.(*decision); changed["server_admins"] || d.about(changed) {
+			c.order.Remove(element)
+			delete(c.entries, d.key)
+		}
+
+		element = next
+	}
+}
+
+// forgetAll drops every decision, for changes that can affect anyone's,
+// like identity links, imports and repairs.
+func (c *decisionCache) forgetAll() {
+	c.forget("server_admins")
+}
+
+// forget drops the decisions about groups after their members changed. The
+// handlers of the admin tools remember none.
+func (h *permissionsHandler) forget(groups ...string) {
+	if h.handlerState != nil {
+		h.decisions.forget(groups...)
+	}
+}
+
+func (h *permissionsHandler) forgetAll() {
+	if h.handlerState != nil {
+		h.decisions.forgetAll()
+	}
+}
+
+func (d *decision) about(groups map[string]bool) bool {
+	for _, perm := range d.perms {
+		if groups[perm] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// perform decides the requests, falling back to what we remember and the
+// outage policy if the store doesn't answer.
+func (h *permissionsHandler) perform(requests []*permsrv.PermissionsRequest) ([]bool, error) {
+	for request := range requests {
+		canonicalNames(requests[request].PermissionsList)
+	}
+
+	var canPerform []bool
+	var err error
+
+	// While the store is down only one caller at a time finds out whether
+	// it's back, once per outageRecheck, everyone else decides without it
+	// straight away rather than piling up behind the failing calls.
+	attempts := performAttempts
+	if until := atomic.LoadInt64(&h.unreachableUntil); until != 0 {
+		attempts = 0
+		if time.Now().UnixNano() >= until && atomic.CompareAndSwapInt64(&h.unreachableUntil, until, time.Now().Add(outageRecheck).UnixNano()) {
+			attempts = 1
+		}
+	}
+
+	backoff := performBackoff
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		var admin []bool
+		generation := h.decisions.current()
+		if canPerform, admin, err = h.decide(requests); err == nil {
+			atomic.StoreInt64(&h.unreachableUntil, 0)
+			for request := range requests {
+				h.decisions.put(requests[request], canPerform[request], generation)
+			}
+
+			h.countDecisions(requests, canPerform, admin, decidedByStore)
+			return canPerform, nil
+		}
+
+		// We refused the request, asking again won't change that.
+		if _, ok := err.(*errors.Error); ok {
+			return nil, err
+		}
+
+		// The first caller to see the store fail keeps retrying, the ones
+		// failing at the same time give up on it like everyone after them.
+		if attempt == 0 && attempts > 1 && !atomic.CompareAndSwapInt64(&h.unreachableUntil, 0, time.Now().Add(outageRecheck).UnixNano()) {
+			break
+		}
+	}
+
+	if err != nil {
+		h.log.Warn("Deciding without the store", zap.Error(err))
+		atomic.StoreInt64(&h.unreachableUntil, time.Now().Add(outageRecheck).UnixNano())
+	}
+
+	canPerform = make([]bool, len(requests))
+	for request := range requests {
+		allowed, ok := h.decisions.get(requests[request])
+		source := decidedByCache
+		if !ok {
+			allowed, source = h.outage.allows(requests[request].PermissionsList), decidedByPolicy
+		}
+
+		canPerform[request] = allowed
+		h.countDecisions(requests[request:request+1], canPerform[request:request+1], nil, source)
+	}
+
+	return canPerform, nil
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"golang.org/x/net/context"
+)
+
+// newOutageHandler returns a handler remembering its decisions, with
+// discord:10 in fleet and the open group failing open.
+func newOutageHandler(t *testing.T) (*permissionsHandler, *miniredis.Miniredis) {
+	h, m := newTestHandler(t, "1")
+	h.decisions = newDecisionCache(0, 0)
+	h.outage = outagePolicy{permissions: map[string]bool{"open": true}}
+
+	ctx := context.Background()
+	if err := h.AddPermission(ctx, &permsrv.Permission{Name: "fleet"}, &permsrv.Permission{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "10", Permission: "fleet"}, &permsrv.PermissionUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return h, m
+}
+
+func canPerform(t *testing.T, h *permissionsHandler, user string, perms ...string) bool {
+	response := &permsrv.PerformResponse{}
+	if err := h.Perform(context.Background(), &permsrv.PermissionsRequest{User: user, PermissionsList: perms}, response); err != nil {
+		t.Fatalf("%s %v: %s", user, perms, err)
+	}
+
+	return response.CanPerform
+}
+
+func TestOutageAnswersFromCacheAndPolicy(t *testing.T) {
+	h, m := newOutageHandler(t)
+
+	if !canPerform(t, h, "<@10>", "fleet") || canPerform(t, h, "20", "fleet") {
+		t.Fatal("the store decided wrong")
+	}
+
+	m.Close()
+
+	// Remembered under the identity, however the caller names it.
+	if !canPerform(t, h, "discord:10", "FLEET") {
+		t.Error("a remembered allow was forgotten during the outage")
+	}
+
+	if canPerform(t, h, "<@!20>", "fleet") {
+		t.Error("a remembered deny was forgotten during the outage")
+	}
+
+	if atomic.LoadInt64(&h.unreachableUntil) == 0 {
+		t.Error("the store failing didn't hold off further attempts")
+	}
+
+	if canPerform(t, h, "30", "fleet") {
+		t.Error("an unknown decision failed open for a closed group")
+	}
+
+	if !canPerform(t, h, "30", "fleet", "open") {
+		t.Error("an unknown decision failed closed for an open group")
+	}
+}
+
+func TestOutageRetriesAndProbes(t *testing.T) {
+	h, m := newOutageHandler(t)
+	m.Close()
+
+	// The first caller keeps trying while the store comes back.
+	m.SAdd(h.Redis.KeyName("members:fleet"), "discord:40")
+	go func() {
+		time.Sleep(performBackoff / 2)
+		m.Restart()
+	}()
+
+	if !canPerform(t, h, "40", "fleet") {
+		t.Fatal("the retry didn't reach the store")
+	}
+
+	if atomic.LoadInt64(&h.unreachableUntil) != 0 {
+		t.Error("the store answering didn't end the outage")
+	}
+
+	m.Close()
+	canPerform(t, h, "50", "fleet")
+
+	// While it's down only a probe once in a while asks it.
+	m.SAdd(h.Redis.KeyName("members:fleet"), "discord:50")
+	if err := m.Restart(); err != nil {
+		t.Fatal(err)
+	}
+
+	if canPerform(t, h, "50", "fleet") {
+		t.Error("asked the store before it was time to probe it")
+	}
+
+	atomic.StoreInt64(&h.unreachableUntil, time.Now().UnixNano())
+	if !canPerform(t, h, "50", "fleet") {
+		t.Error("the probe didn't reach the store")
+	}
+
+	if atomic.LoadInt64(&h.unreachableUntil) != 0 {
+		t.Error("a successful probe didn't end the outage")
+	}
+}
+
+func TestMembershipChangesForgetDecisions(t *testing.T) {
+	h, m := newOutageHandler(t)
+	ctx := context.Background()
+
+	if err := h.AddPermission(ctx, &permsrv.Permission{Name: "scouts"}, &permsrv.Permission{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !canPerform(t, h, "10", "fleet") || canPerform(t, h, "20", "scouts") || canPerform(t, h, "30", "ships") {
+		t.Fatal("the store decided wrong")
+	}
+
+	if err := h.RemovePermissionUser(ctx, &permsrv.PermissionUser{User: "<@10>", Permission: "fleet"}, &permsrv.PermissionUser{}); err != nil {
+		t.Fatal(err)
+	}
+
+	response := &permsrv.BulkPermissionUsersResponse{}
+	if err := h.AddPermissionUsers(ctx, &permsrv.BulkPermissionUsers{Users: []string{"20"}, Permissions: []string{"scouts"}}, response); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only what's remembered can tell these apart from the policy.
+	h.outage.permissions["scouts"] = true
+	h.outage.permissions["ships"] = true
+	m.Close()
+
+	if canPerform(t, h, "10", "fleet") {
+		t.Error("still allowed after leaving the group")
+	}
+
+	if !canPerform(t, h, "20", "scouts") {
+		t.Error("still denied after joining the group")
+	}
+
+	if canPerform(t, h, "30", "ships") {
+		t.Error("a decision about a group that didn't change was forgotten")
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// matches reports whether name passes the page's filters. Filters ignore case.
+func matches(name string, page *permsrv.PageRequest) bool {
+	if page == nil {
+		return true
+	}
+
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, strings.ToLower(page.Prefix)) &&
+		strings.Contains(name, strings.ToLower(page.Contains))
+}
+
+// paginate sorts the names and cuts out the requested page. Page tokens hold
+// the last name of the previous page, so pages stay stable while groups and
+// members come and go. Requests without a page get everything, which is what
+// callers from before paging expect.
+func paginate(names []string, page *permsrv.PageRequest) ([]string, string, error) {
+	var selected []string
+	for name := range names {
+		if matches(names[name], page) {
+			selected = append(selected, names[name])
+		}
+	}
+
+	sort.Strings(selected)
+
+	if page == nil {
+		return selected, "", nil
+	}
+
+	if page.Token != "" {
+		after, err := base64.RawURLEncoding.DecodeString(page.Token)
+		if err != nil {
+			return nil, "", invalidRequest("Invalid page token.")
+		}
+
+		start := sort.SearchStrings(selected, string(after))
+		if start < len(selected) && selected[start] == string(after) {
+			start++
+		}
+
+		selected = selected[start:]
+	}
+
+	size := int(page.Size)
+	if size <= 0 {
+		size = defaultPageSize
+	}
+
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	if len(selected) <= size {
+		return selected, "", nil
+	}
+
+	selected = selected[:size]
+	return selected, base64.RawURLEncoding.EncodeToString([]byte(selected[size-1])), nil
+}
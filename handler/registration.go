@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"fmt"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	goredis "github.com/go-redis/redis"
+	"golang.org/x/net/context"
+)
+
+// Command services declare the groups they check at startup, either for the
+// whole service or per command. We keep track of who declared what in three
+// places:
+//
+//	services:<registrant>  set of groups the registrant declared last time
+//	owners:<group>         set of registrants currently declaring the group
+//	registered             set of every group ever declared by a service
+//
+// The registrant is the service, or service/command when a command registers
+// on its own, so commands of the same service don't undo each other's
+// declarations. A registered group nobody owns anymore is reported as
+// undeclared so an admin can decide whether to remove it. We never delete
+// groups ourselves.
+
+// registrant is who a registration is recorded for.
+func registrant(request *permsrv.PermissionsRegistration) string {
+	if request.Command == "" {
+		return request.Service
+	}
+
+	return request.Service + "/" + request.Command
+}
+
+func (h *permissionsHandler) RegisterPermissions(ctx context.Context, request *permsrv.PermissionsRegistration, response *permsrv.RegistrationResponse) error {
+	h = h.withContext(ctx)
+
+	if request.Service == "" {
+		return invalidRequest("Registering permissions requires a service name.")
+	}
+
+	// A service asking for a group we'd refuse to create has a bug, better
+	// it finds out before anything is stored.
+	for perm := range request.PermissionsList {
+		name := canonicalName(request.PermissionsList[perm].Name)
+		if name != "server_admins" {
+			if _, err := checkName(request.PermissionsList[perm].Name); err != nil {
+				return invalidName(err)
+			}
+		}
+
+		request.PermissionsList[perm].Name = name
+	}
+
+	owner := registrant(request)
+	serviceKey := h.Redis.KeyName(fmt.Sprintf("services:%s", owner))
+
+	// Nearly every command lists server_admins, it always exists and isn't
+	// anybody's to own.
+	var wanted []*permsrv.Permission
+	declared := make(map[string]bool)
+	keys := []string{serviceKey}
+	for perm := range request.PermissionsList {
+		name := request.PermissionsList[perm].Name
+		if name == "server_admins" || declared[name] {
+			continue
+		}
+
+		declared[name] = true
+		wanted = append(wanted, request.PermissionsList[perm])
+		keys = append(keys, h.Redis.KeyName(fmt.Sprintf("description:%s", name)))
+	}
+
+	err := h.watch(func(tx *goredis.Tx) error {
+		response.Created = nil
+
+		previous, err := tx.SMembers(serviceKey).Result()
+
+		if err != nil {
+			return err
+		}
+
+		missing := make([]bool, len(wanted))
+		for perm := range wanted {
+			exists, err := tx.Exists(keys[perm+1]).Result()
+
+			if err != nil {
+				return err
+			}
+
+			missing[perm] = exists == 0
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			for perm := range wanted {
+				name := wanted[perm].Name
+
+				if missing[perm] {
+					group := &permsrv.Permission{
+						Name:        name,
+						Description: wanted[perm].Description,
+						Owner:       owner,
+						Creator:     owner,
+						Tags:        wanted[perm].Tags,
+						System:      true,
+					}
+
+					pipe.Set(keys[perm+1], group.Description, 0)
+					if err := h.created(pipe, group); err != nil {
+						return err
+					}
+
+					response.Created = append(response.Created, group)
+				}
+
+				pipe.SAdd(h.Redis.KeyName(fmt.Sprintf("owners:%s", name)), owner)
+				pipe.SAdd(serviceKey, name)
+				pipe.SAdd(h.Redis.KeyName("registered"), name)
+			}
+
+			for perm := range previous {
+				if !declared[previous[perm]] {
+					pipe.SRem(h.Redis.KeyName(fmt.Sprintf("owners:%s", previous[perm])), owner)
+					pipe.SRem(serviceKey, previous[perm])
+				}
+			}
+
+			if len(response.Created) == 0 {
+				return nil
+			}
+
+			var created []string
+			for perm := range response.Created {
+				created = append(created, response.Created[perm].Name)
+			}
+
+			return h.audit(pipe, "RegisterPermissions", append([]string{owner}, created...)...)
+		})
+
+		return err
+	}, keys...)
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	if err != nil {
+		return err
+	}
+
+	response.Undeclared, err = h.undeclaredPermissions()
+	return err
+}
+
+func (h *permissionsHandler) ListUndeclaredPermissions(ctx context.Context, request *permsrv.NilRequest, response *permsrv.PermissionsResponse) error {
+	h = h.withContext(ctx)
+
+	revision, err := h.revision()
+	if err != nil {
+		return err
+	}
+
+	undeclared, err := h.undeclaredPermissions()
+	if err != nil {
+		return err
+	}
+
+	response.PermissionsList = undeclared
+	response.Revision = revision
+	return nil
+}
+
+func (h *permissionsHandler) undeclaredPermissions() ([]*permsrv.Permission, error) {
+	var undeclared []string
+
+	registered, err := h.Redis.Client.SMembers(h.Redis.KeyName("registered")).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for perm := range registered {
+		owners, err := h.Redis.Client.SCard(h.Redis.KeyName(fmt.Sprintf("owners:%s", registered[perm]))).Result()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if owners == 0 {
+			undeclared = append(undeclared, registered[perm])
+		}
+	}
+
+	return h.describe(undeclared)
+}
+
+// forgetRegistration drops the registration bookkeeping for a group that is
+// being removed.
+func (h *permissionsHandler) forgetRegistration(name string) error {
+	ownersKey := h.Redis.KeyName(fmt.Sprintf("owners:%s", name))
+
+	owners, err := h.Redis.Client.SMembers(ownersKey).Result()
+
+	if err != nil {
+		return err
+	}
+
+	pipe := h.Redis.Client.TxPipeline()
+	for owner := range owners {
+		pipe.SRem(h.Redis.KeyName(fmt.Sprintf("services:%s", owners[owner])), name)
+	}
+	pipe.Del(ownersKey)
+	pipe.SRem(h.Redis.KeyName("registered"), name)
+
+	_, err = pipe.Exec()
+	return err
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	"go.uber.org/zap"
+)
+
+// The layout of our keys is versioned:
+//
+//	schema       the number of migrations applied so far
+//	schema:lock  held by the replica running migrations
+//
+// Every replica runs the missing migrations at startup, one at a time thanks
+// to the lock, and refuses to start against a schema newer than it knows.
+// Migrations must be safe to run again, a replica can die halfway through
+// one. Only ever append to the list.
+var migrations = []struct {
+	description string
+	migrate     func(h *permissionsHandler) error
+}{
+	{"Lower case group names", (*permissionsHandler).canonicalizeNames},
+	{"Members stored as platform:id identities", (*permissionsHandler).migrateMembers},
+}
+
+// schemaVersion is the schema this version of the service writes, which
+// permsrv.SchemaVersion announces to clients.
+var schemaVersion = int64(len(migrations))
+
+// A replica dying while holding the lock mustn't keep the others from
+// starting forever, so the lock expires unless the replica holding it keeps
+// renewing it. Tests shorten these.
+var (
+	schemaLockTimeout = 5 * time.Minute
+	schemaLockRenew   = schemaLockTimeout / 5
+	schemaLockPoll    = time.Second
+)
+
+// unlockScript releases the lock only if we still hold it.
+var unlockScript = goredis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// renewScript extends the lock only if we still hold it.
+var renewScript = goredis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// setSchemaScript records a migration only if we still hold the lock, a
+// replica that lost it mustn't claim work someone else may be redoing.
+var setSchemaScript = goredis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("set", KEYS[2], ARGV[2])
+end
+return false
+`)
+
+// newerSchemaError is the version of a schema newer than ours.
+type newerSchemaError int64
+
+func (e newerSchemaError) Error() string {
+	return fmt.Sprintf("The permissions database is at schema %d, this version of perms-srv only knows up to %d. Upgrade perms-srv.", int64(e), schemaVersion)
+}
+
+// migrate brings the schema up to date.
+func (h *permissionsHandler) migrate() error {
+	version, err := h.schema()
+	if err != nil {
+		return err
+	}
+
+	if version > schemaVersion {
+		return newerSchemaError(version)
+	}
+
+	if version == schemaVersion {
+		return nil
+	}
+
+	lock, err := h.lockSchema()
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	// Another replica may have migrated while we waited for the lock.
+	if version, err = h.schema(); err != nil {
+		return err
+	}
+
+	for ; version < schemaVersion; version++ {
+		h.log.Info("Migrating the permissions database", zap.Int64("schema", version+1), zap.String("migration", migrations[version].description))
+
+		if err = migrations[version].migrate(h); err != nil {
+			return fmt.Errorf("Migrating to schema %d failed: %s", version+1, err)
+		}
+
+		if err = lock.setSchema(version + 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// schema returns the version of the stored schema, 0 for databases from
+// before it was versioned.
+func (h *permissionsHandler) schema() (int64, error) {
+	version, err := h.Redis.Client.Get(h.Redis.KeyName("schema")).Int64()
+
+	if err == redis.Nil {
+		return 0, nil
+	}
+
+	return version, err
+}
+
+// schemaLock is the schema lock while we hold it.
+type schemaLock struct {
+	h     *permissionsHandler
+	key   string
+	token string
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// lockSchema waits until it holds the schema lock and keeps renewing it until
+// it's unlocked.
+func (h *permissionsHandler) lockSchema() (*schemaLock, error) {
+	// Any random string will do, it only has to be ours.
+	token, err := newPrincipalId()
+	if err != nil {
+		return nil, err
+	}
+
+	lock := h.Redis.KeyName("schema:lock")
+	deadline := time.Now().Add(schemaLockTimeout + schemaLockPoll)
+
+	for {
+		locked, err := h.Redis.Client.SetNX(lock, token, schemaLockTimeout).Result()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if locked {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("Timed out waiting for another replica to finish migrating the permissions database.")
+		}
+
+		time.Sleep(schemaLockPoll)
+	}
+
+	l := &schemaLock{h: h, key: lock, token: token, stop: make(chan struct{}), done: make(chan struct{})}
+	go l.renew()
+	return l, nil
+}
+
+// renew extends the lock until it's unlocked or lost.
+func (l *schemaLock) renew() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(schemaLockRenew)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		renewed, err := renewScript.Run(l.h.Redis.Client, []string{l.key}, l.token, schemaLockTimeout.Milliseconds()).Int64()
+
+		if err != nil {
+			// It's still ours until it expires, try again next time.
+			l.h.log.Warn("Couldn't renew the schema lock", zap.Error(err))
+			continue
+		}
+
+		if renewed == 0 {
+			l.h.log.Error("Lost the schema lock while migrating")
+			return
+		}
+	}
+}
+
+// setSchema records the schema version, failing if the lock was lost.
+func (l *schemaLock) setSchema(version int64) error {
+	err := setSchemaScript.Run(l.h.Redis.Client, []string{l.key, l.h.Redis.KeyName("schema")}, l.token, version).Err()
+
+	if err == redis.Nil {
+		return fmt.Errorf("Lost the schema lock before recording schema %d, another replica may be migrating.", version)
+	}
+
+	return err
+}
+
+// unlock stops renewing the lock and releases it.
+func (l *schemaLock) unlock() {
+	close(l.stop)
+	<-l.done
+
+	if err := unlockScript.Run(l.h.Redis.Client, []string{l.key}, l.token).Err(); err != nil {
+		l.h.log.Error("Couldn't release the schema lock", zap.Error(err))
+	}
+}
+
+// migrateMembers rewrites members stored as Discord mentions or bare ids as
+// identities.
+func (h *permissionsHandler) migrateMembers() error {
+	report, err := migrateStore(h.Redis, MigrateOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, invalid := range report.Invalid {
+		h.log.Warn("Can't migrate", zap.String("what", invalid))
+	}
+
+	if len(report.Problems) > 0 {
+		return fmt.Errorf("The migrated members don't match: %v", report.Problems)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// shortSchemaLock makes the schema lock expire, renew and poll quickly.
+func shortSchemaLock(t *testing.T) {
+	timeout, renew, poll := schemaLockTimeout, schemaLockRenew, schemaLockPoll
+	schemaLockTimeout, schemaLockRenew, schemaLockPoll = 200*time.Millisecond, 20*time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { schemaLockTimeout, schemaLockRenew, schemaLockPoll = timeout, renew, poll })
+}
+
+// replica returns another replica sharing h's store.
+func replica(h *permissionsHandler) *permissionsHandler {
+	return &permissionsHandler{Redis: h.Redis, handlerState: &handlerState{log: zap.NewNop()}}
+}
+
+func TestSchemaLockIsRenewed(t *testing.T) {
+	shortSchemaLock(t)
+	h, m := newTestHandler(t)
+
+	lock, err := h.lockSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// miniredis only expires keys when told time passed, nearly all of the
+	// timeout at a time, so only renewing keeps the lock.
+	for i := 0; i < 5; i++ {
+		m.FastForward(schemaLockTimeout * 3 / 4)
+		time.Sleep(3 * schemaLockRenew)
+
+		if token, _ := m.Get(lock.key); token != lock.token {
+			t.Fatalf("lost the lock after %d renewals", i)
+		}
+	}
+
+	lock.unlock()
+	if m.Exists(lock.key) {
+		t.Error("unlocking left the lock behind")
+	}
+}
+
+func TestSchemaLockContention(t *testing.T) {
+	shortSchemaLock(t)
+	h, m := newTestHandler(t)
+
+	first, err := h.lockSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The other replica waits until the first is done.
+	locked := make(chan *schemaLock)
+	go func() {
+		second, err := replica(h).lockSchema()
+		if err != nil {
+			t.Error(err)
+		}
+
+		locked <- second
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("two replicas held the lock")
+	case <-time.After(5 * schemaLockPoll):
+	}
+
+	first.unlock()
+	second := <-locked
+	if second == nil {
+		t.FailNow()
+	}
+
+	// The first one's late unlock or schema update doesn't touch the
+	// second one's lock.
+	if err = unlockScript.Run(h.Redis.Client, []string{first.key}, first.token).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if token, _ := m.Get(second.key); token != second.token {
+		t.Error("another replica released the lock")
+	}
+
+	if err = first.setSchema(1); err == nil || !strings.Contains(err.Error(), "Lost the schema lock") {
+		t.Errorf("recording a migration without the lock got %v", err)
+	}
+
+	// A replica that never lets go is given up on.
+	if _, err = replica(h).lockSchema(); err == nil || !strings.Contains(err.Error(), "Timed out") {
+		t.Errorf("waiting for a lock that's never released got %v", err)
+	}
+
+	second.unlock()
+}
+
+func TestReplicasMigrateOnce(t *testing.T) {
+	shortSchemaLock(t)
+	source, m := newLegacyStore(t)
+	m.Del("perms:description:Ships")
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = replica(&permissionsHandler{Redis: source}).migrate()
+		}(i)
+	}
+
+	wg.Wait()
+	for i := range errs {
+		if errs[i] != nil {
+			t.Errorf("replica %d: %s", i, errs[i])
+		}
+	}
+
+	if schema, _ := m.Get("perms:schema"); schema != "2" {
+		t.Errorf("the store is at schema %q, want 2", schema)
+	}
+
+	var migrations int
+	audit, _ := m.List("perms:audit")
+	for _, entry := range audit {
+		if strings.Contains(entry, `"Action":"Import"`) {
+			migrations++
+		}
+	}
+
+	if migrations != 1 {
+		t.Errorf("migrated %d times, want once", migrations)
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	permsrv "github.com/chremoas/perms-srv/proto"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	bmemory "github.com/micro/go-micro/broker/memory"
+	"github.com/micro/go-micro/client"
+	rmemory "github.com/micro/go-micro/registry/memory"
+	"github.com/micro/go-micro/server"
+	tmemory "github.com/micro/go-micro/transport/memory"
+	"go.uber.org/zap"
+)
+
+// newTestService serves a handler backed by miniredis and returns the
+// generated client for it along with the Redis it uses. The admin group
+// exists with admins in it.
+func newTestService(t *testing.T, admins ...string) (permsrv.PermissionsService, *miniredis.Miniredis) {
+	h, m := newTestHandler(t, admins...)
+	return serve(t, h, nil, nil), m
+}
+
+// newTestHandler returns a ready handler backed by miniredis, with the admin
+// group and admins in it.
+func newTestHandler(t *testing.T, admins ...string) (*permissionsHandler, *miniredis.Miniredis) {
+	m, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(m.Close)
+
+	h := &permissionsHandler{
+		Redis:        &redis.Client{Client: goredis.NewClient(&goredis.Options{Addr: m.Addr()}), Prefix: "perms"},
+		handlerState: &handlerState{log: zap.NewNop()},
+	}
+
+	m.Set(h.Redis.KeyName("description:server_admins"), "Server Admins")
+	for admin := range admins {
+		m.SAdd(h.Redis.KeyName("members:server_admins"), "discord:"+admins[admin])
+	}
+
+	if err = h.prepare(); err != nil {
+		t.Fatal(err)
+	}
+
+	return h, m
+}
+
+// serve serves h over go-micro's in memory registry, broker and transport
+// and returns the generated client for it.
+func serve(t *testing.T, h *permissionsHandler, serverOptions []server.Option, clientOptions []client.Option) permsrv.PermissionsService {
+	registry, transport, broker := rmemory.NewRegistry(), tmemory.NewTransport(), bmemory.NewBroker()
+	if err := broker.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := server.NewServer(append([]server.Option{
+		server.Name("perms-test"),
+		server.Registry(registry),
+		server.Transport(transport),
+		server.Broker(broker),
+	}, serverOptions...)...)
+	permsrv.RegisterPermissionsHandler(srv, h)
+
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Stop() })
+
+	return permsrv.NewPermissionsService("perms-test", client.NewClient(append([]client.Option{
+		client.Registry(registry),
+		client.Transport(transport),
+		client.Broker(broker),
+	}, clientOptions...)...))
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	"github.com/micro/go-micro/errors"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+)
+
+// People sign in to the web UI with a key of their own, issued by an admin
+// with `perms-srv ui-key <user>`. The UI trades it for a session cookie, so
+// the browser never holds the API token, and changes made in the UI are made
+// as the user the key was issued to, not one the browser names.
+//
+// We only keep hashes: uikeys:<hash> says whose a key is and uikey:<user>
+// which key a user has, so issuing another revokes the old one.
+// sessions:<hash> names the key a session was opened with, revoking the key
+// ends its sessions too.
+const (
+	sessionCookie = "perms_session"
+	sessionAge    = 12 * time.Hour
+)
+
+var (
+	errInvalidUIKey = errors.Unauthorized(permsrv.ReasonUnauthorized, "That isn't a valid UI key, ask an admin for one.")
+	errSignedOut    = errors.Unauthorized(permsrv.ReasonUnauthorized, "Your session ended, sign in again.")
+)
+
+// IssueUIKey gives user a new key to sign in to the web UI with, revoking
+// the one they had. Only its hash is stored, the key can't be shown again.
+func IssueUIKey(client *redis.Client, user string) (string, error) {
+	return (&permissionsHandler{Redis: client}).issueUIKey(user)
+}
+
+// RevokeUIKey takes away user's key and ends the sessions opened with it.
+func RevokeUIKey(client *redis.Client, user string) error {
+	return (&permissionsHandler{Redis: client}).revokeUIKey(user)
+}
+
+func (h *permissionsHandler) issueUIKey(user string) (string, error) {
+	identity, err := parseIdentity(user)
+	if err != nil {
+		return "", err
+	}
+
+	user = identityString(identity)
+	userKey := h.Redis.KeyName(fmt.Sprintf("uikey:%s", user))
+
+	key, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+
+	err = h.watch(func(tx *goredis.Tx) error {
+		previous, err := tx.Get(userKey).Result()
+
+		if err != nil && err != goredis.Nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			if previous != "" {
+				pipe.Del(h.Redis.KeyName(fmt.Sprintf("uikeys:%s", previous)))
+			}
+
+			pipe.Set(h.Redis.KeyName(fmt.Sprintf("uikeys:%s", hashSecret(key))), user, 0)
+			pipe.Set(userKey, hashSecret(key), 0)
+			return h.audit(pipe, "IssueUIKey", user)
+		})
+
+		return err
+	}, userKey)
+
+	if err == goredis.TxFailedErr {
+		return "", errChanged
+	}
+
+	return key, err
+}
+
+func (h *permissionsHandler) revokeUIKey(user string) error {
+	identity, err := parseIdentity(user)
+	if err != nil {
+		return err
+	}
+
+	user = identityString(identity)
+	userKey := h.Redis.KeyName(fmt.Sprintf("uikey:%s", user))
+
+	err = h.watch(func(tx *goredis.Tx) error {
+		previous, err := tx.Get(userKey).Result()
+
+		if err == goredis.Nil {
+			return errors.NotFound(permsrv.ReasonInvalidUser, "`%s` has no UI key.", user)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Pipelined(func(pipe goredis.Pipeliner) error {
+			pipe.Del(h.Redis.KeyName(fmt.Sprintf("uikeys:%s", previous)), userKey)
+			return h.audit(pipe, "RevokeUIKey", user)
+		})
+
+		return err
+	}, userKey)
+
+	if err == goredis.TxFailedErr {
+		return errChanged
+	}
+
+	return err
+}
+
+// openSession starts a web UI session for the owner of key and returns its
+// id, which goes in the session cookie, and who it belongs to.
+func (h *permissionsHandler) openSession(ctx context.Context, key string) (string, string, error) {
+	h = h.withContext(ctx)
+
+	user, err := h.Redis.Client.Get(h.Redis.KeyName(fmt.Sprintf("uikeys:%s", hashSecret(key)))).Result()
+
+	if err == goredis.Nil {
+		return "", "", errInvalidUIKey
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	id, err := newSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = h.Redis.Client.Set(h.Redis.KeyName(fmt.Sprintf("sessions:%s", hashSecret(id))), hashSecret(key), sessionAge).Err()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	h.log.Info("Signed in to the web UI", zap.String("user", user))
+	return id, user, nil
+}
+
+// sessionUser returns who the session with id belongs to.
+func (h *permissionsHandler) sessionUser(ctx context.Context, id string) (string, error) {
+	h = h.withContext(ctx)
+
+	key, err := h.Redis.Client.Get(h.Redis.KeyName(fmt.Sprintf("sessions:%s", hashSecret(id)))).Result()
+
+	if err == goredis.Nil {
+		return "", errSignedOut
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	user, err := h.Redis.Client.Get(h.Redis.KeyName(fmt.Sprintf("uikeys:%s", key))).Result()
+
+	if err == goredis.Nil {
+		return "", errSignedOut
+	}
+
+	return user, err
+}
+
+func (h *permissionsHandler) closeSession(ctx context.Context, id string) error {
+	h = h.withContext(ctx)
+	return h.Redis.Client.Del(h.Redis.KeyName(fmt.Sprintf("sessions:%s", hashSecret(id)))).Err()
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// hashSecret is what we store instead of a key or session id, so reading the
+// store doesn't let anyone sign in.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
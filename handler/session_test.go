@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+)
+
+func TestUISessions(t *testing.T) {
+	h, _ := newTestHandler(t, "1")
+
+	viper.Set("perms.http.token", testToken)
+	t.Cleanup(func() { viper.Set("perms.http.token", "") })
+
+	api, err := NewHTTPHandler(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(method, path, body string, headers map[string]string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		for key, value := range headers {
+			r.Header.Set(key, value)
+		}
+
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, r)
+		return w
+	}
+
+	ui := map[string]string{uiHeader: "1"}
+	signIn := func(key string) *http.Cookie {
+		w := send("POST", sessionPath, `{"Key": "`+key+`"}`, ui, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("signing in got %d: %s", w.Code, w.Body)
+		}
+
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteStrictMode {
+			t.Fatalf("got cookies %v, want one HttpOnly, SameSite=Strict session", cookies)
+		}
+
+		return cookies[0]
+	}
+
+	admin, err := IssueUIKey(h.Redis, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := IssueUIKey(h.Redis, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w := send("POST", sessionPath, `{"Key": "guess"}`, ui, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("signing in with a made up key got %d", w.Code)
+	}
+
+	if w := send("POST", sessionPath, `{"Key": "`+other+`"}`, nil, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("signing in without %s got %d", uiHeader, w.Code)
+	}
+
+	session := signIn(other)
+	w := send("GET", sessionPath, "", ui, session)
+	var who struct{ User string }
+	if json.NewDecoder(w.Body).Decode(&who); who.User != "discord:2" {
+		t.Errorf("signed in as %q, want discord:2", who.User)
+	}
+
+	// Naming someone else doesn't make the session theirs.
+	impersonating := map[string]string{uiHeader: "1", httpUserHeader: "1"}
+	if w := send("POST", "/v1/groups", `{"Name": "fleet"}`, impersonating, session); w.Code != http.StatusForbidden {
+		t.Errorf("a change in a session of a user without permission got %d: %s", w.Code, w.Body)
+	}
+
+	if w := send("GET", "/v1/groups", "", ui, session); w.Code != http.StatusOK {
+		t.Errorf("reading in a session got %d: %s", w.Code, w.Body)
+	}
+
+	// Other sites can get the cookie sent, but not the header.
+	session = signIn(admin)
+	if w := send("POST", "/v1/groups", `{"Name": "fleet"}`, nil, session); w.Code != http.StatusUnauthorized {
+		t.Errorf("a change with the cookie alone got %d", w.Code)
+	}
+
+	if w := send("POST", "/v1/groups", `{"Name": "fleet"}`, ui, session); w.Code != http.StatusOK {
+		t.Errorf("a change in an admin's session got %d: %s", w.Code, w.Body)
+	}
+
+	// A new key ends the sessions opened with the old one.
+	if _, err = IssueUIKey(h.Redis, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := send("GET", "/v1/groups", "", ui, session); w.Code != http.StatusUnauthorized {
+		t.Errorf("a session of a replaced key got %d", w.Code)
+	}
+
+	session = signIn(other)
+	if w := send("DELETE", sessionPath, "", ui, session); w.Code != http.StatusNoContent {
+		t.Errorf("signing out got %d", w.Code)
+	}
+
+	if w := send("GET", sessionPath, "", ui, session); w.Code != http.StatusUnauthorized {
+		t.Errorf("a closed session got %d", w.Code)
+	}
+
+	// Services with the token still name who they act for.
+	token := map[string]string{"Authorization": "Bearer " + testToken, httpUserHeader: "1"}
+	if w := send("POST", "/v1/groups", `{"Name": "doctrine"}`, token, nil); w.Code != http.StatusOK {
+		t.Errorf("a change with the token got %d: %s", w.Code, w.Body)
+	}
+
+	audit := &permsrv.AuditResponse{}
+	if err = h.ListAuditEntries(context.Background(), &permsrv.AuditRequest{}, audit); err != nil {
+		t.Fatal(err)
+	}
+
+	var added []string
+	for _, entry := range audit.Entries {
+		if entry.Action == "AddPermission" {
+			added = append(added, entry.Details[0]+" by "+entry.Actor)
+		}
+	}
+
+	if strings.Join(added, ", ") != "doctrine by discord:1, fleet by discord:1" {
+		t.Errorf("got %v added, want doctrine and fleet by the admin", added)
+	}
+
+	if err = RevokeUIKey(h.Redis, "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = h.openSession(context.Background(), other); err != errInvalidUIKey {
+		t.Errorf("signing in with a revoked key got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+	"github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/metadata"
+	"github.com/micro/go-micro/server"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"golang.org/x/net/context"
+)
+
+// Every RPC gets a span, continuing the caller's trace when it sent one, and
+// every store call made answering it a child span. Tests can record the
+// spans in memory with opentracing's mocktracer.
+
+// TraceWrapper starts a span with tracer for every RPC.
+func TraceWrapper(tracer opentracing.Tracer) server.HandlerWrapper {
+	return func(fn server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, request server.Request, response interface{}) error {
+			md, _ := metadata.FromContext(ctx)
+			parent, _ := tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(md))
+
+			span := tracer.StartSpan(request.Endpoint(), ext.RPCServerOption(parent))
+			defer span.Finish()
+			ext.Component.Set(span, "perms-srv")
+
+			err := fn(opentracing.ContextWithSpan(ctx, span), request, response)
+
+			span.SetTag("outcome", outcome(err))
+			if err != nil {
+				span.LogFields(otlog.String("message", detail(err)))
+
+				// Refusing a bad request isn't an error on our side.
+				if e, ok := err.(*errors.Error); !ok || e.Code >= 500 {
+					ext.Error.Set(span, true)
+				}
+			}
+
+			return err
+		}
+	}
+}
+
+// withContext returns the handler to answer a request with, whose store calls
+// are part of the trace in ctx if there is one and whose changes are audited
+// as made by the user the request was made for.
+func (h *permissionsHandler) withContext(ctx context.Context) *permissionsHandler {
+	md, _ := metadata.FromContext(ctx)
+	actor := md[httpUserHeader]
+	if identity, err := parseIdentity(actor); err == nil {
+		actor = identityString(identity)
+	}
+
+	if opentracing.SpanFromContext(ctx) == nil {
+		if actor == h.actor {
+			return h
+		}
+
+		return &permissionsHandler{Redis: h.Redis, actor: actor, handlerState: h.handlerState}
+	}
+
+	client := h.Redis.Client.WithContext(ctx)
+	client.WrapProcess(traceProcess(ctx))
+	client.WrapProcessPipeline(tracePipeline(ctx))
+
+	return &permissionsHandler{
+		Redis:        &redis.Client{Client: client, Prefix: h.Redis.Prefix},
+		actor:        actor,
+		handlerState: h.handlerState,
+	}
+}
+
+// watch runs fn in a transaction watching keys. The transaction is timed and
+// traced like the client it's made from.
+func (h *permissionsHandler) watch(fn func(tx *goredis.Tx) error, keys ...string) error {
+	ctx := h.Redis.Client.Context()
+	traced := opentracing.SpanFromContext(ctx) != nil
+
+	if traced {
+		var span opentracing.Span
+		span, ctx = storeSpan(ctx, "watch")
+		defer span.Finish()
+	}
+
+	return h.Redis.Client.Watch(func(tx *goredis.Tx) error {
+		tx.WrapProcess(timeProcess)
+		tx.WrapProcessPipeline(timePipeline)
+
+		if traced {
+			tx.WrapProcess(traceProcess(ctx))
+			tx.WrapProcessPipeline(tracePipeline(ctx))
+		}
+
+		return fn(tx)
+	}, keys...)
+}
+
+func traceProcess(ctx context.Context) func(process func(cmd goredis.Cmder) error) func(cmd goredis.Cmder) error {
+	return func(process func(cmd goredis.Cmder) error) func(cmd goredis.Cmder) error {
+		return func(cmd goredis.Cmder) error {
+			span, _ := storeSpan(ctx, cmd.Name())
+			defer span.Finish()
+
+			err := process(cmd)
+			storeError(span, err)
+			return err
+		}
+	}
+}
+
+func tracePipeline(ctx context.Context) func(process func(cmds []goredis.Cmder) error) func(cmds []goredis.Cmder) error {
+	return func(process func(cmds []goredis.Cmder) error) func(cmds []goredis.Cmder) error {
+		return func(cmds []goredis.Cmder) error {
+			span, _ := storeSpan(ctx, "pipeline")
+			defer span.Finish()
+			span.SetTag("redis.commands", len(cmds))
+
+			err := process(cmds)
+			storeError(span, err)
+			return err
+		}
+	}
+}
+
+// storeSpan starts a span for a store call, a child of the one in ctx and
+// recorded by the same tracer.
+func storeSpan(ctx context.Context, name string) (opentracing.Span, context.Context) {
+	span, ctx := opentracing.StartSpanFromContextWithTracer(ctx, opentracing.SpanFromContext(ctx).Tracer(), name)
+	ext.SpanKindRPCClient.Set(span)
+	ext.DBType.Set(span, "redis")
+	return span, ctx
+}
+
+func storeError(span opentracing.Span, err error) {
+	// A lookup of a key that isn't there still succeeded, so the span isn't
+	// marked failed for it.
+	if err != nil && err != redis.Nil {
+		ext.Error.Set(span, true)
+		span.LogFields(otlog.Error(err))
+	}
+}
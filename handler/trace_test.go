@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"testing"
+
+	perms "github.com/chremoas/perms-srv/client"
+	permsrv "github.com/chremoas/perms-srv/proto"
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/server"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"golang.org/x/net/context"
+)
+
+func TestTracesRPCsAndStoreCalls(t *testing.T) {
+	tracer := mocktracer.New()
+	h, m := newTestHandler(t)
+	service := serve(t, h,
+		[]server.Option{server.WrapHandler(TraceWrapper(tracer))},
+		[]client.Option{client.Wrap(perms.TraceWrapper(tracer))})
+
+	m.Set(h.Redis.KeyName("description:fleet"), "Fleet")
+
+	root := tracer.StartSpan("command")
+	ctx := opentracing.ContextWithSpan(context.Background(), root)
+
+	if _, err := service.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "1", Permission: "fleet"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.AddPermissionUser(ctx, &permsrv.PermissionUser{User: "1", Permission: "fleet"}); perms.Reason(err) != permsrv.ReasonAlreadyMember {
+		t.Fatalf("got %v, want %s", err, permsrv.ReasonAlreadyMember)
+	}
+
+	root.Finish()
+
+	spans := make(map[int]*mocktracer.MockSpan)
+	for _, span := range tracer.FinishedSpans() {
+		spans[span.SpanContext.SpanID] = span
+		if span.SpanContext.TraceID != root.(*mocktracer.MockSpan).SpanContext.TraceID {
+			t.Errorf("span %s isn't part of the caller's trace", span.OperationName)
+		}
+	}
+
+	// under is the RPC span a span was made answering, if any.
+	under := func(span *mocktracer.MockSpan) *mocktracer.MockSpan {
+		for span != nil && span.OperationName != "Permissions.AddPermissionUser" {
+			span = spans[span.ParentID]
+		}
+
+		return span
+	}
+
+	var calls, rpcs []*mocktracer.MockSpan
+	store := make(map[*mocktracer.MockSpan][]string)
+	for _, span := range tracer.FinishedSpans() {
+		switch {
+		case span.OperationName == "perms-test Permissions.AddPermissionUser":
+			calls = append(calls, span)
+		case span.OperationName == "Permissions.AddPermissionUser":
+			rpcs = append(rpcs, span)
+		case span.Tag("db.type") == "redis":
+			if rpc := under(span); rpc != nil {
+				store[rpc] = append(store[rpc], span.OperationName)
+			} else {
+				t.Errorf("store span %s isn't part of an RPC", span.OperationName)
+			}
+		}
+	}
+
+	if len(calls) != 2 || len(rpcs) != 2 {
+		t.Fatalf("got %d client and %d server spans, want 2 each", len(calls), len(rpcs))
+	}
+
+	for call := range calls {
+		if calls[call].ParentID != root.(*mocktracer.MockSpan).SpanContext.SpanID {
+			t.Errorf("client span %d isn't a child of the caller's span", call)
+		}
+
+		if rpcs[call].ParentID != calls[call].SpanContext.SpanID {
+			t.Errorf("server span %d isn't a child of the client span", call)
+		}
+
+		if len(store[rpcs[call]]) == 0 {
+			t.Errorf("server span %d has no store spans", call)
+		}
+	}
+
+	if outcome := rpcs[0].Tag("outcome"); outcome != "ok" {
+		t.Errorf("got outcome %v, want ok", outcome)
+	}
+
+	// Refusing a request isn't an error on our side.
+	if outcome, failed := rpcs[1].Tag("outcome"), rpcs[1].Tag("error"); outcome != permsrv.ReasonAlreadyMember || failed != nil {
+		t.Errorf("got outcome %v and error %v for a refused request", outcome, failed)
+	}
+
+	for _, name := range []string{"watch", "exists", "pipeline"} {
+		found := false
+		for _, op := range store[rpcs[0]] {
+			found = found || op == name
+		}
+
+		if !found {
+			t.Errorf("no %s span in %v", name, store[rpcs[0]])
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package handler
+
+// The web UI is a single page served at / next to the HTTP API, for those
+// managing permissions without the chat commands. It only calls the API.
+// People sign in with their own UI key, which the page trades for a session
+// cookie it can't read, and changes are made as them, so they must hold one
+// of perms.http.permissions.
+const (
+	uiContentType = "text/html; charset=utf-8"
+	// Nothing but the page itself and calls to the API.
+	uiSecurityPolicy = "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'; form-action 'none'; frame-ancestors 'none'; base-uri 'none'"
+)
+
+const uiPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Permissions</title>
+<style>
+body { font-family: sans-serif; margin: 0; color: #222; background: #f6f6f6; }
+header { background: #2b3a4a; color: #fff; padding: 0.6em 1em; display: flex; align-items: center; gap: 1em; }
+header h1 { font-size: 1.2em; margin: 0; flex: 1; }
+header button { background: none; color: #fff; border: 1px solid #fff; }
+nav button.active { background: #fff; color: #2b3a4a; }
+main { padding: 1em; max-width: 60em; margin: auto; }
+section { background: #fff; border: 1px solid #ddd; border-radius: 4px; padding: 1em; margin-bottom: 1em; }
+h2 { font-size: 1.1em; margin-top: 0; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: 0.3em 0.5em; border-bottom: 1px solid #eee; vertical-align: top; }
+tr.selectable { cursor: pointer; }
+tr.selectable:hover { background: #eef3f8; }
+form { display: flex; flex-wrap: wrap; gap: 0.5em; margin: 0.5em 0; }
+input { padding: 0.3em; }
+button { padding: 0.3em 0.8em; cursor: pointer; }
+button.danger { color: #a00; }
+.muted { color: #777; }
+#message { padding: 0.6em 1em; display: none; }
+#message.error { display: block; background: #fbe3e3; color: #800; }
+#message.info { display: block; background: #e3f4e3; color: #060; }
+[hidden] { display: none !important; }
+</style>
+</head>
+<body>
+<header>
+  <h1>Permissions</h1>
+  <nav id="tabs" hidden>
+    <button data-view="groups">Groups</button>
+    <button data-view="users">Users</button>
+    <button data-view="audit">History</button>
+  </nav>
+  <span id="who"></span>
+  <button id="signout" hidden>Sign out</button>
+</header>
+<div id="message"></div>
+<main>
+  <section id="signin">
+    <h2>Sign in</h2>
+    <p class="muted">Ask whoever runs the bot for your UI key. Changes are made as you, so you must be allowed to change permissions.</p>
+    <form id="signin-form">
+      <input id="signin-key" type="password" placeholder="UI key" required autocomplete="off">
+      <button>Sign in</button>
+    </form>
+  </section>
+
+  <div id="groups" hidden>
+    <section>
+      <h2>Groups</h2>
+      <form id="group-search">
+        <input id="group-filter" placeholder="Name contains">
+        <button>Search</button>
+      </form>
+      <table>
+        <thead><tr><th>Name</th><th>Description</th><th>Owner</th></tr></thead>
+        <tbody id="group-list"></tbody>
+      </table>
+      <button id="group-more" hidden>More</button>
+      <h2 style="margin-top: 1em">New group</h2>
+      <form id="group-add">
+        <input id="group-add-name" placeholder="Name" required>
+        <input id="group-add-description" placeholder="Description" size="40">
+        <button>Create</button>
+      </form>
+    </section>
+    <section id="group" hidden>
+      <h2 id="group-name"></h2>
+      <p id="group-description"></p>
+      <p class="muted" id="group-details"></p>
+      <table>
+        <thead><tr><th>Member</th><th></th></tr></thead>
+        <tbody id="member-list"></tbody>
+      </table>
+      <button id="member-more" hidden>More</button>
+      <form id="member-add">
+        <input id="member-add-user" placeholder="User" required>
+        <button>Add member</button>
+      </form>
+      <button id="group-remove" class="danger">Delete group</button>
+    </section>
+  </div>
+
+  <div id="users" hidden>
+    <section>
+      <h2>Look up a user</h2>
+      <form id="user-search">
+        <input id="user-name" placeholder="User" required>
+        <button>Look up</button>
+      </form>
+    </section>
+    <section id="user" hidden>
+      <h2 id="user-title"></h2>
+      <table>
+        <thead><tr><th>Group</th><th>Description</th><th></th></tr></thead>
+        <tbody id="user-groups"></tbody>
+      </table>
+      <form id="user-add">
+        <input id="user-add-group" placeholder="Group" required>
+        <button>Add to group</button>
+      </form>
+    </section>
+  </div>
+
+  <div id="audit" hidden>
+    <section>
+      <h2>History</h2>
+      <form id="audit-search">
+        <input id="audit-limit" type="number" min="1" max="10000" value="100">
+        <button>Show</button>
+      </form>
+      <table>
+        <thead><tr><th>When</th><th>Who</th><th>What</th><th>Details</th></tr></thead>
+        <tbody id="audit-list"></tbody>
+      </table>
+    </section>
+  </div>
+</main>
+<script>
+"use strict";
+
+function $(id) { return document.getElementById(id); }
+
+// el builds an element, children given as strings are text and never markup.
+function el(tag, props, children) {
+  var node = document.createElement(tag);
+  Object.keys(props || {}).forEach(function (key) { node[key] = props[key]; });
+  (children || []).forEach(function (child) {
+    node.appendChild(typeof child === "string" ? document.createTextNode(child) : child);
+  });
+  return node;
+}
+
+function show(text, kind) {
+  $("message").textContent = text;
+  $("message").className = text ? kind : "";
+}
+
+function path() {
+  return "/" + Array.prototype.map.call(arguments, encodeURIComponent).join("/");
+}
+
+// api calls the API as the signed in user, the browser sends the session
+// cookie along.
+async function api(method, url, body) {
+  var headers = { "X-Perms-Ui": "1" };
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+
+  var response = await fetch(url, { method: method, headers: headers, body: body === undefined ? undefined : JSON.stringify(body) });
+  var data = await response.json().catch(function () { return {}; });
+  if (!response.ok) {
+    if (response.status === 401) {
+      signedOut();
+    }
+    throw new Error(data.detail || response.statusText);
+  }
+  return data;
+}
+
+// act runs a change and reports how it went.
+async function act(fn, done) {
+  try {
+    await fn();
+    show(done, "info");
+  } catch (err) {
+    show(err.message, "error");
+  }
+}
+
+function signedIn(user) {
+  $("signin").hidden = true;
+  $("tabs").hidden = false;
+  $("signout").hidden = false;
+  $("who").textContent = user;
+  view("groups");
+}
+
+function signedOut() {
+  $("signin").hidden = false;
+  $("tabs").hidden = true;
+  $("signout").hidden = true;
+  $("who").textContent = "";
+  ["groups", "users", "audit"].forEach(function (name) { $(name).hidden = true; });
+}
+
+function view(name) {
+  ["groups", "users", "audit"].forEach(function (other) { $(other).hidden = other !== name; });
+  document.querySelectorAll("#tabs button").forEach(function (tab) {
+    tab.className = tab.dataset.view === name ? "active" : "";
+  });
+  show("");
+
+  if (name === "groups") {
+    loadGroups(false);
+  } else if (name === "audit") {
+    loadAudit();
+  }
+}
+
+var groups = { next: "" };
+
+async function loadGroups(more) {
+  var query = new URLSearchParams({ contains: $("group-filter").value });
+  if (more) {
+    query.set("token", groups.next);
+  } else {
+    $("group-list").textContent = "";
+  }
+
+  try {
+    var data = await api("GET", "/v1/groups?" + query);
+    (data.PermissionsList || []).forEach(function (group) {
+      var row = el("tr", { className: "selectable" }, [
+        el("td", {}, [group.Name]),
+        el("td", {}, [group.Description || ""]),
+        el("td", {}, [group.Owner || ""])
+      ]);
+      row.onclick = function () { loadGroup(group.Name); };
+      $("group-list").appendChild(row);
+    });
+    groups.next = data.NextPageToken || "";
+    $("group-more").hidden = !groups.next;
+  } catch (err) {
+    show(err.message, "error");
+  }
+}
+
+var group = { name: "", revision: 0, next: "" };
+
+async function loadGroup(name) {
+  try {
+    var data = await api("GET", "/v1/groups" + path(name));
+    group.name = data.Name;
+    $("group-name").textContent = data.Name;
+    $("group-description").textContent = data.Description || "";
+
+    var details = [];
+    if (data.Owner) { details.push("Owned by " + data.Owner); }
+    if (data.Tags && data.Tags.length) { details.push("Tags: " + data.Tags.join(", ")); }
+    if (data.System) { details.push("Declared by a service"); }
+    $("group-details").textContent = details.join(". ");
+
+    $("group").hidden = false;
+    await loadMembers(false);
+  } catch (err) {
+    show(err.message, "error");
+  }
+}
+
+async function loadMembers(more) {
+  var query = new URLSearchParams();
+  if (more) {
+    query.set("token", group.next);
+  } else {
+    $("member-list").textContent = "";
+  }
+
+  var data = await api("GET", "/v1/groups" + path(group.name, "members") + "?" + query);
+  group.revision = data.Revision || 0;
+  (data.UserList || []).forEach(function (user) {
+    var remove = el("button", { className: "danger" }, ["Remove"]);
+    remove.onclick = function () {
+      if (confirm("Remove " + user + " from " + group.name + "?")) {
+        act(async function () {
+          await api("DELETE", "/v1/groups" + path(group.name, "members", user) + "?expected_revision=" + group.revision);
+          await loadMembers(false);
+        }, "Removed " + user + " from " + group.name + ".");
+      }
+    };
+    $("member-list").appendChild(el("tr", {}, [el("td", {}, [user]), el("td", {}, [remove])]));
+  });
+  group.next = data.NextPageToken || "";
+  $("member-more").hidden = !group.next;
+}
+
+async function loadUser(user) {
+  var data = await api("GET", "/v1/users" + path(user, "groups") + "?size=1000");
+  $("user-title").textContent = user;
+  $("user-groups").textContent = "";
+  (data.PermissionsList || []).forEach(function (group) {
+    var remove = el("button", { className: "danger" }, ["Remove"]);
+    remove.onclick = function () {
+      if (confirm("Remove " + user + " from " + group.Name + "?")) {
+        act(async function () {
+          await api("DELETE", "/v1/groups" + path(group.Name, "members", user));
+          await loadUser(user);
+        }, "Removed " + user + " from " + group.Name + ".");
+      }
+    };
+    $("user-groups").appendChild(el("tr", {}, [
+      el("td", {}, [group.Name]),
+      el("td", {}, [group.Description || ""]),
+      el("td", {}, [remove])
+    ]));
+  });
+  if (!(data.PermissionsList || []).length) {
+    $("user-groups").appendChild(el("tr", {}, [el("td", { className: "muted", colSpan: 3 }, ["Not in any group."])]));
+  }
+  $("user").hidden = false;
+}
+
+async function loadAudit() {
+  try {
+    var data = await api("GET", "/v1/audit?limit=" + encodeURIComponent($("audit-limit").value));
+    $("audit-list").textContent = "";
+    (data.Entries || []).forEach(function (entry) {
+      $("audit-list").appendChild(el("tr", {}, [
+        el("td", {}, [new Date(entry.Time * 1000).toLocaleString()]),
+        el("td", {}, [entry.Actor || ""]),
+        el("td", {}, [entry.Action]),
+        el("td", {}, [(entry.Details || []).join(", ")])
+      ]));
+    });
+  } catch (err) {
+    show(err.message, "error");
+  }
+}
+
+document.querySelectorAll("#tabs button").forEach(function (tab) {
+  tab.onclick = function () { view(tab.dataset.view); };
+});
+
+$("signin-form").onsubmit = async function (event) {
+  event.preventDefault();
+  try {
+    var data = await api("POST", "/ui/session", { Key: $("signin-key").value.trim() });
+    signedIn(data.User);
+  } catch (err) {
+    show(err.message, "error");
+  }
+  $("signin-key").value = "";
+};
+
+$("signout").onclick = async function () {
+  await api("DELETE", "/ui/session").catch(function () {});
+  signedOut();
+};
+
+$("group-search").onsubmit = function (event) {
+  event.preventDefault();
+  loadGroups(false);
+};
+
+$("group-more").onclick = function () { loadGroups(true); };
+
+$("member-more").onclick = function () {
+  loadMembers(true).catch(function (err) { show(err.message, "error"); });
+};
+
+$("group-add").onsubmit = function (event) {
+  event.preventDefault();
+  var name = $("group-add-name").value.trim();
+  act(async function () {
+    await api("POST", "/v1/groups", { Name: name, Description: $("group-add-description").value });
+    $("group-add").reset();
+    await loadGroups(false);
+    await loadGroup(name);
+  }, "Created " + name + ".");
+};
+
+$("group-remove").onclick = function () {
+  var name = group.name;
+  if (confirm("Delete the group " + name + " and take it away from all its members?")) {
+    act(async function () {
+      await api("DELETE", "/v1/groups" + path(name));
+      $("group").hidden = true;
+      await loadGroups(false);
+    }, "Deleted " + name + ".");
+  }
+};
+
+$("member-add").onsubmit = function (event) {
+  event.preventDefault();
+  var user = $("member-add-user").value.trim();
+  act(async function () {
+    await api("POST", "/v1/groups" + path(group.name, "members"), { User: user, ExpectedRevision: group.revision });
+    $("member-add").reset();
+    await loadMembers(false);
+  }, "Added " + user + " to " + group.name + ".");
+};
+
+$("user-search").onsubmit = function (event) {
+  event.preventDefault();
+  loadUser($("user-name").value.trim()).catch(function (err) { show(err.message, "error"); });
+};
+
+$("user-add").onsubmit = function (event) {
+  event.preventDefault();
+  var user = $("user-title").textContent;
+  var name = $("user-add-group").value.trim();
+  act(async function () {
+    await api("POST", "/v1/groups" + path(name, "members"), { User: user });
+    $("user-add").reset();
+    await loadUser(user);
+  }, "Added " + user + " to " + name + ".");
+};
+
+$("audit-search").onsubmit = function (event) {
+  event.preventDefault();
+  loadAudit();
+};
+
+api("GET", "/ui/session").then(function (data) { signedIn(data.User); }, function () {});
+</script>
+</body>
+</html>
+`
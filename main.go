@@ -2,9 +2,13 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"os"
 
 	"github.com/chremoas/services-common/config"
 	"github.com/micro/go-micro"
+	"github.com/micro/go-micro/server"
+	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
 
 	chremoasPrometheus "github.com/chremoas/services-common/prometheus"
@@ -21,14 +25,19 @@ var (
 )
 
 func main() {
+	if isTool(os.Args) {
+		os.Exit(runTool(os.Args[1], os.Args[2:]))
+	}
+
 	var err error
 
-	// TODO pick stuff up from the config
+	// The configuration isn't loaded yet, initialize replaces this logger
+	// with the one configured in perms.log.
 	logger, err = zap.NewProduction()
 	if err != nil {
 		panic(err)
 	}
-	defer logger.Sync()
+	defer func() { logger.Sync() }()
 	logger.Info("Initialized logger")
 
 	go chremoasPrometheus.PrometheusExporter(logger)
@@ -41,6 +50,47 @@ func main() {
 }
 
 func initialize(config *config.Configuration) error {
-	permsrv.RegisterPermissionsHandler(service.Server(), handler.NewPermissionsHandler(config))
+	configured, err := handler.NewLogger()
+	if err != nil {
+		return err
+	}
+
+	logger.Sync()
+	logger = configured
+
+	perms := handler.NewPermissionsHandler(config, logger)
+	permsrv.RegisterPermissionsHandler(service.Server(), perms)
+
+	// Spans go to the global tracer, which does nothing until one is
+	// registered.
+	wrappers := []server.HandlerWrapper{
+		handler.TraceWrapper(opentracing.GlobalTracer()),
+		handler.MetricsWrapper,
+		handler.LogWrapper(logger),
+	}
+
+	for _, wrapper := range wrappers {
+		if err = service.Server().Init(server.WrapHandler(wrapper)); err != nil {
+			return err
+		}
+	}
+
+	if err = service.Server().Init(server.WrapHandler(handler.HealthWrapper(perms))); err != nil {
+		return err
+	}
+
+	if config.Net.ListenPort != 0 {
+		api, err := handler.NewHTTPHandler(perms, wrappers...)
+		if err != nil {
+			return err
+		}
+
+		addr := fmt.Sprintf("%s:%d", config.Net.ListenHost, config.Net.ListenPort)
+		go func() {
+			logger.Info("Serving the HTTP API", zap.String("address", addr))
+			logger.Error("The HTTP API stopped", zap.Error(http.ListenAndServe(addr, api)))
+		}()
+	}
+
 	return nil
 }
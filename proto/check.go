@@ -0,0 +1,24 @@
+package chremoas_perms
+
+// Kinds of problems Check finds. Like the reasons, callers may depend on
+// them.
+const (
+	// CheckOrphanMembers is a members set left behind by a removed group.
+	CheckOrphanMembers = "orphan_members"
+	// CheckDanglingReference is metadata, a registration or an identity
+	// link pointing at something that doesn't exist (anymore).
+	CheckDanglingReference = "dangling_reference"
+	// CheckInvalidName is a group whose name isn't valid anymore.
+	CheckInvalidName = "invalid_name"
+	// CheckInvalidMember is a member that isn't a user we know how to read.
+	CheckInvalidMember = "invalid_member"
+	// CheckLegacyMember is a member stored as a Discord mention or bare id.
+	CheckLegacyMember = "legacy_member"
+	// CheckDuplicateMember is someone stored more than once in a group.
+	CheckDuplicateMember = "duplicate_member"
+	// CheckDuplicateIdentity is an identity linked to more than one
+	// principal.
+	CheckDuplicateIdentity = "duplicate_identity"
+	// CheckNoAdmins means server_admins is missing or empty.
+	CheckNoAdmins = "no_admins"
+)
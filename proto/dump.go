@@ -0,0 +1,97 @@
+package chremoas_perms
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The document Export returns and Import takes. Keep it backwards
+// compatible, people keep their backups around for a long time.
+
+// DumpVersion is the version of the format Export writes. Import refuses
+// anything else, older versions will need converting when it changes.
+const DumpVersion = 1
+
+// Dump formats and import modes.
+const (
+	DumpJSON = "json"
+	DumpYAML = "yaml"
+
+	ImportMerge   = "merge"
+	ImportReplace = "replace"
+)
+
+// Dump is the exported database.
+type Dump struct {
+	Version  int          `json:"version" yaml:"version"`
+	Exported int64        `json:"exported" yaml:"exported"`
+	Groups   []*DumpGroup `json:"groups" yaml:"groups"`
+	// Identities lists the identities linked to each principal.
+	Identities map[string][]string `json:"identities,omitempty" yaml:"identities,omitempty"`
+}
+
+// DumpGroup is a group with its metadata, members and the services declaring
+// it.
+type DumpGroup struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Owner       string   `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Creator     string   `json:"creator,omitempty" yaml:"creator,omitempty"`
+	Created     int64    `json:"created,omitempty" yaml:"created,omitempty"`
+	Updated     int64    `json:"updated,omitempty" yaml:"updated,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	System      bool     `json:"system,omitempty" yaml:"system,omitempty"`
+	Members     []string `json:"members,omitempty" yaml:"members,omitempty"`
+	Services    []string `json:"services,omitempty" yaml:"services,omitempty"`
+	Registered  bool     `json:"registered,omitempty" yaml:"registered,omitempty"`
+}
+
+// DumpFormat returns the format name for format, JSON if it's empty.
+func DumpFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", DumpJSON:
+		return DumpJSON, nil
+	case DumpYAML, "yml":
+		return DumpYAML, nil
+	}
+
+	return "", fmt.Errorf("Unknown format `%s`, use json or yaml.", format)
+}
+
+// Marshal encodes the dump in the given format.
+func (d *Dump) Marshal(format string) ([]byte, error) {
+	format, err := DumpFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == DumpYAML {
+		return yaml.Marshal(d)
+	}
+
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// UnmarshalDump decodes a dump in the given format.
+func UnmarshalDump(data []byte, format string) (*Dump, error) {
+	format, err := DumpFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	dump := &Dump{}
+	if format == DumpYAML {
+		err = yaml.Unmarshal(data, dump)
+	} else {
+		err = json.Unmarshal(data, dump)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read the %s dump: %s.", format, err)
+	}
+
+	return dump, nil
+}
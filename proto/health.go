@@ -0,0 +1,5 @@
+package chremoas_perms
+
+// SchemaVersion is the version of the Redis layout this perms-srv migrates
+// its store to and reports in HealthResponse.
+const SchemaVersion = 2
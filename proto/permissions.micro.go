@@ -1,32 +1,18 @@
 // Code generated by protoc-gen-micro. DO NOT EDIT.
 // source: permissions.proto
 
-/*
-Package chremoas_perms is a generated protocol buffer package.
-
-It is generated from these files:
-	permissions.proto
-
-It has these top-level messages:
-	NilRequest
-	UsersRequest
-	UsersResponse
-	PermissionsRequest
-	Permission
-	PermissionUser
-	PermissionsResponse
-	PerformResponse
-*/
 package chremoas_perms
 
-import proto "github.com/golang/protobuf/proto"
-import fmt "fmt"
-import math "math"
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
 
 import (
+	context "context"
 	client "github.com/micro/go-micro/client"
 	server "github.com/micro/go-micro/server"
-	context "context"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -38,7 +24,7 @@ var _ = math.Inf
 // is compatible with the proto package it is being compiled against.
 // A compilation error at this line likely means your copy of the
 // proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ context.Context
@@ -49,13 +35,32 @@ var _ server.Option
 
 type PermissionsService interface {
 	Perform(ctx context.Context, in *PermissionsRequest, opts ...client.CallOption) (*PerformResponse, error)
+	PerformBatch(ctx context.Context, in *PerformBatchRequest, opts ...client.CallOption) (*PerformBatchResponse, error)
 	AddPermission(ctx context.Context, in *Permission, opts ...client.CallOption) (*Permission, error)
 	AddPermissionUser(ctx context.Context, in *PermissionUser, opts ...client.CallOption) (*PermissionUser, error)
+	UpdatePermission(ctx context.Context, in *Permission, opts ...client.CallOption) (*Permission, error)
+	RenamePermission(ctx context.Context, in *RenamePermissionRequest, opts ...client.CallOption) (*Permission, error)
 	RemovePermission(ctx context.Context, in *Permission, opts ...client.CallOption) (*Permission, error)
 	RemovePermissionUser(ctx context.Context, in *PermissionUser, opts ...client.CallOption) (*PermissionUser, error)
+	AddPermissionUsers(ctx context.Context, in *BulkPermissionUsers, opts ...client.CallOption) (*BulkPermissionUsersResponse, error)
+	RemovePermissionUsers(ctx context.Context, in *BulkPermissionUsers, opts ...client.CallOption) (*BulkPermissionUsersResponse, error)
+	SetPermissionUsers(ctx context.Context, in *SetPermissionUsersRequest, opts ...client.CallOption) (*SetPermissionUsersResponse, error)
 	ListPermissions(ctx context.Context, in *NilRequest, opts ...client.CallOption) (*PermissionsResponse, error)
+	ListPermissionsPage(ctx context.Context, in *ListPermissionsRequest, opts ...client.CallOption) (*PermissionsResponse, error)
+	GetPermission(ctx context.Context, in *Permission, opts ...client.CallOption) (*Permission, error)
 	ListPermissionUsers(ctx context.Context, in *UsersRequest, opts ...client.CallOption) (*UsersResponse, error)
 	ListUserPermissions(ctx context.Context, in *PermissionUser, opts ...client.CallOption) (*PermissionsResponse, error)
+	ListUserPermissionsPage(ctx context.Context, in *UserPermissionsRequest, opts ...client.CallOption) (*PermissionsResponse, error)
+	LinkIdentity(ctx context.Context, in *IdentityLink, opts ...client.CallOption) (*Principal, error)
+	UnlinkIdentity(ctx context.Context, in *Identity, opts ...client.CallOption) (*Principal, error)
+	GetPrincipal(ctx context.Context, in *Identity, opts ...client.CallOption) (*Principal, error)
+	RegisterPermissions(ctx context.Context, in *PermissionsRegistration, opts ...client.CallOption) (*RegistrationResponse, error)
+	ListUndeclaredPermissions(ctx context.Context, in *NilRequest, opts ...client.CallOption) (*PermissionsResponse, error)
+	ListAuditEntries(ctx context.Context, in *AuditRequest, opts ...client.CallOption) (*AuditResponse, error)
+	Export(ctx context.Context, in *ExportRequest, opts ...client.CallOption) (*ExportResponse, error)
+	Import(ctx context.Context, in *ImportRequest, opts ...client.CallOption) (*ImportResponse, error)
+	Check(ctx context.Context, in *CheckRequest, opts ...client.CallOption) (*CheckResponse, error)
+	Health(ctx context.Context, in *NilRequest, opts ...client.CallOption) (*HealthResponse, error)
 }
 
 type permissionsService struct {
@@ -86,6 +91,16 @@ func (c *permissionsService) Perform(ctx context.Context, in *PermissionsRequest
 	return out, nil
 }
 
+func (c *permissionsService) PerformBatch(ctx context.Context, in *PerformBatchRequest, opts ...client.CallOption) (*PerformBatchResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.PerformBatch", in)
+	out := new(PerformBatchResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *permissionsService) AddPermission(ctx context.Context, in *Permission, opts ...client.CallOption) (*Permission, error) {
 	req := c.c.NewRequest(c.name, "Permissions.AddPermission", in)
 	out := new(Permission)
@@ -106,6 +121,26 @@ func (c *permissionsService) AddPermissionUser(ctx context.Context, in *Permissi
 	return out, nil
 }
 
+func (c *permissionsService) UpdatePermission(ctx context.Context, in *Permission, opts ...client.CallOption) (*Permission, error) {
+	req := c.c.NewRequest(c.name, "Permissions.UpdatePermission", in)
+	out := new(Permission)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) RenamePermission(ctx context.Context, in *RenamePermissionRequest, opts ...client.CallOption) (*Permission, error) {
+	req := c.c.NewRequest(c.name, "Permissions.RenamePermission", in)
+	out := new(Permission)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *permissionsService) RemovePermission(ctx context.Context, in *Permission, opts ...client.CallOption) (*Permission, error) {
 	req := c.c.NewRequest(c.name, "Permissions.RemovePermission", in)
 	out := new(Permission)
@@ -126,6 +161,36 @@ func (c *permissionsService) RemovePermissionUser(ctx context.Context, in *Permi
 	return out, nil
 }
 
+func (c *permissionsService) AddPermissionUsers(ctx context.Context, in *BulkPermissionUsers, opts ...client.CallOption) (*BulkPermissionUsersResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.AddPermissionUsers", in)
+	out := new(BulkPermissionUsersResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) RemovePermissionUsers(ctx context.Context, in *BulkPermissionUsers, opts ...client.CallOption) (*BulkPermissionUsersResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.RemovePermissionUsers", in)
+	out := new(BulkPermissionUsersResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) SetPermissionUsers(ctx context.Context, in *SetPermissionUsersRequest, opts ...client.CallOption) (*SetPermissionUsersResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.SetPermissionUsers", in)
+	out := new(SetPermissionUsersResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *permissionsService) ListPermissions(ctx context.Context, in *NilRequest, opts ...client.CallOption) (*PermissionsResponse, error) {
 	req := c.c.NewRequest(c.name, "Permissions.ListPermissions", in)
 	out := new(PermissionsResponse)
@@ -136,6 +201,26 @@ func (c *permissionsService) ListPermissions(ctx context.Context, in *NilRequest
 	return out, nil
 }
 
+func (c *permissionsService) ListPermissionsPage(ctx context.Context, in *ListPermissionsRequest, opts ...client.CallOption) (*PermissionsResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.ListPermissionsPage", in)
+	out := new(PermissionsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) GetPermission(ctx context.Context, in *Permission, opts ...client.CallOption) (*Permission, error) {
+	req := c.c.NewRequest(c.name, "Permissions.GetPermission", in)
+	out := new(Permission)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *permissionsService) ListPermissionUsers(ctx context.Context, in *UsersRequest, opts ...client.CallOption) (*UsersResponse, error) {
 	req := c.c.NewRequest(c.name, "Permissions.ListPermissionUsers", in)
 	out := new(UsersResponse)
@@ -156,29 +241,177 @@ func (c *permissionsService) ListUserPermissions(ctx context.Context, in *Permis
 	return out, nil
 }
 
+func (c *permissionsService) ListUserPermissionsPage(ctx context.Context, in *UserPermissionsRequest, opts ...client.CallOption) (*PermissionsResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.ListUserPermissionsPage", in)
+	out := new(PermissionsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) LinkIdentity(ctx context.Context, in *IdentityLink, opts ...client.CallOption) (*Principal, error) {
+	req := c.c.NewRequest(c.name, "Permissions.LinkIdentity", in)
+	out := new(Principal)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) UnlinkIdentity(ctx context.Context, in *Identity, opts ...client.CallOption) (*Principal, error) {
+	req := c.c.NewRequest(c.name, "Permissions.UnlinkIdentity", in)
+	out := new(Principal)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) GetPrincipal(ctx context.Context, in *Identity, opts ...client.CallOption) (*Principal, error) {
+	req := c.c.NewRequest(c.name, "Permissions.GetPrincipal", in)
+	out := new(Principal)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) RegisterPermissions(ctx context.Context, in *PermissionsRegistration, opts ...client.CallOption) (*RegistrationResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.RegisterPermissions", in)
+	out := new(RegistrationResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) ListUndeclaredPermissions(ctx context.Context, in *NilRequest, opts ...client.CallOption) (*PermissionsResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.ListUndeclaredPermissions", in)
+	out := new(PermissionsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) ListAuditEntries(ctx context.Context, in *AuditRequest, opts ...client.CallOption) (*AuditResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.ListAuditEntries", in)
+	out := new(AuditResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) Export(ctx context.Context, in *ExportRequest, opts ...client.CallOption) (*ExportResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.Export", in)
+	out := new(ExportResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) Import(ctx context.Context, in *ImportRequest, opts ...client.CallOption) (*ImportResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.Import", in)
+	out := new(ImportResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) Check(ctx context.Context, in *CheckRequest, opts ...client.CallOption) (*CheckResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.Check", in)
+	out := new(CheckResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionsService) Health(ctx context.Context, in *NilRequest, opts ...client.CallOption) (*HealthResponse, error) {
+	req := c.c.NewRequest(c.name, "Permissions.Health", in)
+	out := new(HealthResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Permissions service
 
 type PermissionsHandler interface {
 	Perform(context.Context, *PermissionsRequest, *PerformResponse) error
+	PerformBatch(context.Context, *PerformBatchRequest, *PerformBatchResponse) error
 	AddPermission(context.Context, *Permission, *Permission) error
 	AddPermissionUser(context.Context, *PermissionUser, *PermissionUser) error
+	UpdatePermission(context.Context, *Permission, *Permission) error
+	RenamePermission(context.Context, *RenamePermissionRequest, *Permission) error
 	RemovePermission(context.Context, *Permission, *Permission) error
 	RemovePermissionUser(context.Context, *PermissionUser, *PermissionUser) error
+	AddPermissionUsers(context.Context, *BulkPermissionUsers, *BulkPermissionUsersResponse) error
+	RemovePermissionUsers(context.Context, *BulkPermissionUsers, *BulkPermissionUsersResponse) error
+	SetPermissionUsers(context.Context, *SetPermissionUsersRequest, *SetPermissionUsersResponse) error
 	ListPermissions(context.Context, *NilRequest, *PermissionsResponse) error
+	ListPermissionsPage(context.Context, *ListPermissionsRequest, *PermissionsResponse) error
+	GetPermission(context.Context, *Permission, *Permission) error
 	ListPermissionUsers(context.Context, *UsersRequest, *UsersResponse) error
 	ListUserPermissions(context.Context, *PermissionUser, *PermissionsResponse) error
+	ListUserPermissionsPage(context.Context, *UserPermissionsRequest, *PermissionsResponse) error
+	LinkIdentity(context.Context, *IdentityLink, *Principal) error
+	UnlinkIdentity(context.Context, *Identity, *Principal) error
+	GetPrincipal(context.Context, *Identity, *Principal) error
+	RegisterPermissions(context.Context, *PermissionsRegistration, *RegistrationResponse) error
+	ListUndeclaredPermissions(context.Context, *NilRequest, *PermissionsResponse) error
+	ListAuditEntries(context.Context, *AuditRequest, *AuditResponse) error
+	Export(context.Context, *ExportRequest, *ExportResponse) error
+	Import(context.Context, *ImportRequest, *ImportResponse) error
+	Check(context.Context, *CheckRequest, *CheckResponse) error
+	Health(context.Context, *NilRequest, *HealthResponse) error
 }
 
 func RegisterPermissionsHandler(s server.Server, hdlr PermissionsHandler, opts ...server.HandlerOption) {
 	type permissions interface {
 		Perform(ctx context.Context, in *PermissionsRequest, out *PerformResponse) error
+		PerformBatch(ctx context.Context, in *PerformBatchRequest, out *PerformBatchResponse) error
 		AddPermission(ctx context.Context, in *Permission, out *Permission) error
 		AddPermissionUser(ctx context.Context, in *PermissionUser, out *PermissionUser) error
+		UpdatePermission(ctx context.Context, in *Permission, out *Permission) error
+		RenamePermission(ctx context.Context, in *RenamePermissionRequest, out *Permission) error
 		RemovePermission(ctx context.Context, in *Permission, out *Permission) error
 		RemovePermissionUser(ctx context.Context, in *PermissionUser, out *PermissionUser) error
+		AddPermissionUsers(ctx context.Context, in *BulkPermissionUsers, out *BulkPermissionUsersResponse) error
+		RemovePermissionUsers(ctx context.Context, in *BulkPermissionUsers, out *BulkPermissionUsersResponse) error
+		SetPermissionUsers(ctx context.Context, in *SetPermissionUsersRequest, out *SetPermissionUsersResponse) error
 		ListPermissions(ctx context.Context, in *NilRequest, out *PermissionsResponse) error
+		ListPermissionsPage(ctx context.Context, in *ListPermissionsRequest, out *PermissionsResponse) error
+		GetPermission(ctx context.Context, in *Permission, out *Permission) error
 		ListPermissionUsers(ctx context.Context, in *UsersRequest, out *UsersResponse) error
 		ListUserPermissions(ctx context.Context, in *PermissionUser, out *PermissionsResponse) error
+		ListUserPermissionsPage(ctx context.Context, in *UserPermissionsRequest, out *PermissionsResponse) error
+		LinkIdentity(ctx context.Context, in *IdentityLink, out *Principal) error
+		UnlinkIdentity(ctx context.Context, in *Identity, out *Principal) error
+		GetPrincipal(ctx context.Context, in *Identity, out *Principal) error
+		RegisterPermissions(ctx context.Context, in *PermissionsRegistration, out *RegistrationResponse) error
+		ListUndeclaredPermissions(ctx context.Context, in *NilRequest, out *PermissionsResponse) error
+		ListAuditEntries(ctx context.Context, in *AuditRequest, out *AuditResponse) error
+		Export(ctx context.Context, in *ExportRequest, out *ExportResponse) error
+		Import(ctx context.Context, in *ImportRequest, out *ImportResponse) error
+		Check(ctx context.Context, in *CheckRequest, out *CheckResponse) error
+		Health(ctx context.Context, in *NilRequest, out *HealthResponse) error
 	}
 	type Permissions struct {
 		permissions
@@ -195,6 +428,10 @@ func (h *permissionsHandler) Perform(ctx context.Context, in *PermissionsRequest
 	return h.PermissionsHandler.Perform(ctx, in, out)
 }
 
+func (h *permissionsHandler) PerformBatch(ctx context.Context, in *PerformBatchRequest, out *PerformBatchResponse) error {
+	return h.PermissionsHandler.PerformBatch(ctx, in, out)
+}
+
 func (h *permissionsHandler) AddPermission(ctx context.Context, in *Permission, out *Permission) error {
 	return h.PermissionsHandler.AddPermission(ctx, in, out)
 }
@@ -203,6 +440,14 @@ func (h *permissionsHandler) AddPermissionUser(ctx context.Context, in *Permissi
 	return h.PermissionsHandler.AddPermissionUser(ctx, in, out)
 }
 
+func (h *permissionsHandler) UpdatePermission(ctx context.Context, in *Permission, out *Permission) error {
+	return h.PermissionsHandler.UpdatePermission(ctx, in, out)
+}
+
+func (h *permissionsHandler) RenamePermission(ctx context.Context, in *RenamePermissionRequest, out *Permission) error {
+	return h.PermissionsHandler.RenamePermission(ctx, in, out)
+}
+
 func (h *permissionsHandler) RemovePermission(ctx context.Context, in *Permission, out *Permission) error {
 	return h.PermissionsHandler.RemovePermission(ctx, in, out)
 }
@@ -211,10 +456,30 @@ func (h *permissionsHandler) RemovePermissionUser(ctx context.Context, in *Permi
 	return h.PermissionsHandler.RemovePermissionUser(ctx, in, out)
 }
 
+func (h *permissionsHandler) AddPermissionUsers(ctx context.Context, in *BulkPermissionUsers, out *BulkPermissionUsersResponse) error {
+	return h.PermissionsHandler.AddPermissionUsers(ctx, in, out)
+}
+
+func (h *permissionsHandler) RemovePermissionUsers(ctx context.Context, in *BulkPermissionUsers, out *BulkPermissionUsersResponse) error {
+	return h.PermissionsHandler.RemovePermissionUsers(ctx, in, out)
+}
+
+func (h *permissionsHandler) SetPermissionUsers(ctx context.Context, in *SetPermissionUsersRequest, out *SetPermissionUsersResponse) error {
+	return h.PermissionsHandler.SetPermissionUsers(ctx, in, out)
+}
+
 func (h *permissionsHandler) ListPermissions(ctx context.Context, in *NilRequest, out *PermissionsResponse) error {
 	return h.PermissionsHandler.ListPermissions(ctx, in, out)
 }
 
+func (h *permissionsHandler) ListPermissionsPage(ctx context.Context, in *ListPermissionsRequest, out *PermissionsResponse) error {
+	return h.PermissionsHandler.ListPermissionsPage(ctx, in, out)
+}
+
+func (h *permissionsHandler) GetPermission(ctx context.Context, in *Permission, out *Permission) error {
+	return h.PermissionsHandler.GetPermission(ctx, in, out)
+}
+
 func (h *permissionsHandler) ListPermissionUsers(ctx context.Context, in *UsersRequest, out *UsersResponse) error {
 	return h.PermissionsHandler.ListPermissionUsers(ctx, in, out)
 }
@@ -222,3 +487,47 @@ func (h *permissionsHandler) ListPermissionUsers(ctx context.Context, in *UsersR
 func (h *permissionsHandler) ListUserPermissions(ctx context.Context, in *PermissionUser, out *PermissionsResponse) error {
 	return h.PermissionsHandler.ListUserPermissions(ctx, in, out)
 }
+
+func (h *permissionsHandler) ListUserPermissionsPage(ctx context.Context, in *UserPermissionsRequest, out *PermissionsResponse) error {
+	return h.PermissionsHandler.ListUserPermissionsPage(ctx, in, out)
+}
+
+func (h *permissionsHandler) LinkIdentity(ctx context.Context, in *IdentityLink, out *Principal) error {
+	return h.PermissionsHandler.LinkIdentity(ctx, in, out)
+}
+
+func (h *permissionsHandler) UnlinkIdentity(ctx context.Context, in *Identity, out *Principal) error {
+	return h.PermissionsHandler.UnlinkIdentity(ctx, in, out)
+}
+
+func (h *permissionsHandler) GetPrincipal(ctx context.Context, in *Identity, out *Principal) error {
+	return h.PermissionsHandler.GetPrincipal(ctx, in, out)
+}
+
+func (h *permissionsHandler) RegisterPermissions(ctx context.Context, in *PermissionsRegistration, out *RegistrationResponse) error {
+	return h.PermissionsHandler.RegisterPermissions(ctx, in, out)
+}
+
+func (h *permissionsHandler) ListUndeclaredPermissions(ctx context.Context, in *NilRequest, out *PermissionsResponse) error {
+	return h.PermissionsHandler.ListUndeclaredPermissions(ctx, in, out)
+}
+
+func (h *permissionsHandler) ListAuditEntries(ctx context.Context, in *AuditRequest, out *AuditResponse) error {
+	return h.PermissionsHandler.ListAuditEntries(ctx, in, out)
+}
+
+func (h *permissionsHandler) Export(ctx context.Context, in *ExportRequest, out *ExportResponse) error {
+	return h.PermissionsHandler.Export(ctx, in, out)
+}
+
+func (h *permissionsHandler) Import(ctx context.Context, in *ImportRequest, out *ImportResponse) error {
+	return h.PermissionsHandler.Import(ctx, in, out)
+}
+
+func (h *permissionsHandler) Check(ctx context.Context, in *CheckRequest, out *CheckResponse) error {
+	return h.PermissionsHandler.Check(ctx, in, out)
+}
+
+func (h *permissionsHandler) Health(ctx context.Context, in *NilRequest, out *HealthResponse) error {
+	return h.PermissionsHandler.Health(ctx, in, out)
+}
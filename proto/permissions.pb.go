@@ -1,27 +1,13 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // source: permissions.proto
 
-/*
-Package chremoas_perms is a generated protocol buffer package.
-
-It is generated from these files:
-	permissions.proto
-
-It has these top-level messages:
-	NilRequest
-	UsersRequest
-	UsersResponse
-	PermissionsRequest
-	Permission
-	PermissionUser
-	PermissionsResponse
-	PerformResponse
-*/
 package chremoas_perms
 
-import proto "github.com/golang/protobuf/proto"
-import fmt "fmt"
-import math "math"
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -32,24 +18,174 @@ var _ = math.Inf
 // is compatible with the proto package it is being compiled against.
 // A compilation error at this line likely means your copy of the
 // proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type NilRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *NilRequest) Reset()                    { *m = NilRequest{} }
-func (m *NilRequest) String() string            { return proto.CompactTextString(m) }
-func (*NilRequest) ProtoMessage()               {}
-func (*NilRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+func (m *NilRequest) Reset()         { *m = NilRequest{} }
+func (m *NilRequest) String() string { return proto.CompactTextString(m) }
+func (*NilRequest) ProtoMessage()    {}
+func (*NilRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{0}
+}
+
+func (m *NilRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NilRequest.Unmarshal(m, b)
+}
+func (m *NilRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NilRequest.Marshal(b, m, deterministic)
+}
+func (m *NilRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NilRequest.Merge(m, src)
+}
+func (m *NilRequest) XXX_Size() int {
+	return xxx_messageInfo_NilRequest.Size(m)
+}
+func (m *NilRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NilRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NilRequest proto.InternalMessageInfo
+
+type PageRequest struct {
+	Size                 int32    `protobuf:"varint,1,opt,name=Size,proto3" json:"Size,omitempty"`
+	Token                string   `protobuf:"bytes,2,opt,name=Token,proto3" json:"Token,omitempty"`
+	Prefix               string   `protobuf:"bytes,3,opt,name=Prefix,proto3" json:"Prefix,omitempty"`
+	Contains             string   `protobuf:"bytes,4,opt,name=Contains,proto3" json:"Contains,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PageRequest) Reset()         { *m = PageRequest{} }
+func (m *PageRequest) String() string { return proto.CompactTextString(m) }
+func (*PageRequest) ProtoMessage()    {}
+func (*PageRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{1}
+}
+
+func (m *PageRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PageRequest.Unmarshal(m, b)
+}
+func (m *PageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PageRequest.Marshal(b, m, deterministic)
+}
+func (m *PageRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PageRequest.Merge(m, src)
+}
+func (m *PageRequest) XXX_Size() int {
+	return xxx_messageInfo_PageRequest.Size(m)
+}
+func (m *PageRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PageRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PageRequest proto.InternalMessageInfo
+
+func (m *PageRequest) GetSize() int32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *PageRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *PageRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *PageRequest) GetContains() string {
+	if m != nil {
+		return m.Contains
+	}
+	return ""
+}
+
+type ListPermissionsRequest struct {
+	Page                 *PageRequest `protobuf:"bytes,1,opt,name=Page,proto3" json:"Page,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ListPermissionsRequest) Reset()         { *m = ListPermissionsRequest{} }
+func (m *ListPermissionsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListPermissionsRequest) ProtoMessage()    {}
+func (*ListPermissionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{2}
+}
+
+func (m *ListPermissionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListPermissionsRequest.Unmarshal(m, b)
+}
+func (m *ListPermissionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListPermissionsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListPermissionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPermissionsRequest.Merge(m, src)
+}
+func (m *ListPermissionsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListPermissionsRequest.Size(m)
+}
+func (m *ListPermissionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPermissionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListPermissionsRequest proto.InternalMessageInfo
+
+func (m *ListPermissionsRequest) GetPage() *PageRequest {
+	if m != nil {
+		return m.Page
+	}
+	return nil
+}
 
 type UsersRequest struct {
-	Permission string `protobuf:"bytes,1,opt,name=Permission" json:"Permission,omitempty"`
+	Permission           string       `protobuf:"bytes,1,opt,name=Permission,proto3" json:"Permission,omitempty"`
+	Page                 *PageRequest `protobuf:"bytes,2,opt,name=Page,proto3" json:"Page,omitempty"`
+	Platform             string       `protobuf:"bytes,3,opt,name=Platform,proto3" json:"Platform,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *UsersRequest) Reset()         { *m = UsersRequest{} }
+func (m *UsersRequest) String() string { return proto.CompactTextString(m) }
+func (*UsersRequest) ProtoMessage()    {}
+func (*UsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{3}
 }
 
-func (m *UsersRequest) Reset()                    { *m = UsersRequest{} }
-func (m *UsersRequest) String() string            { return proto.CompactTextString(m) }
-func (*UsersRequest) ProtoMessage()               {}
-func (*UsersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (m *UsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UsersRequest.Unmarshal(m, b)
+}
+func (m *UsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UsersRequest.Marshal(b, m, deterministic)
+}
+func (m *UsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UsersRequest.Merge(m, src)
+}
+func (m *UsersRequest) XXX_Size() int {
+	return xxx_messageInfo_UsersRequest.Size(m)
+}
+func (m *UsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UsersRequest proto.InternalMessageInfo
 
 func (m *UsersRequest) GetPermission() string {
 	if m != nil {
@@ -58,14 +194,53 @@ func (m *UsersRequest) GetPermission() string {
 	return ""
 }
 
+func (m *UsersRequest) GetPage() *PageRequest {
+	if m != nil {
+		return m.Page
+	}
+	return nil
+}
+
+func (m *UsersRequest) GetPlatform() string {
+	if m != nil {
+		return m.Platform
+	}
+	return ""
+}
+
 type UsersResponse struct {
-	UserList []string `protobuf:"bytes,1,rep,name=UserList" json:"UserList,omitempty"`
+	UserList             []string `protobuf:"bytes,1,rep,name=UserList,proto3" json:"UserList,omitempty"`
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=NextPageToken,proto3" json:"NextPageToken,omitempty"`
+	Revision             int64    `protobuf:"varint,3,opt,name=Revision,proto3" json:"Revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UsersResponse) Reset()         { *m = UsersResponse{} }
+func (m *UsersResponse) String() string { return proto.CompactTextString(m) }
+func (*UsersResponse) ProtoMessage()    {}
+func (*UsersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{4}
+}
+
+func (m *UsersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UsersResponse.Unmarshal(m, b)
+}
+func (m *UsersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UsersResponse.Marshal(b, m, deterministic)
+}
+func (m *UsersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UsersResponse.Merge(m, src)
+}
+func (m *UsersResponse) XXX_Size() int {
+	return xxx_messageInfo_UsersResponse.Size(m)
+}
+func (m *UsersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UsersResponse.DiscardUnknown(m)
 }
 
-func (m *UsersResponse) Reset()                    { *m = UsersResponse{} }
-func (m *UsersResponse) String() string            { return proto.CompactTextString(m) }
-func (*UsersResponse) ProtoMessage()               {}
-func (*UsersResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+var xxx_messageInfo_UsersResponse proto.InternalMessageInfo
 
 func (m *UsersResponse) GetUserList() []string {
 	if m != nil {
@@ -74,15 +249,99 @@ func (m *UsersResponse) GetUserList() []string {
 	return nil
 }
 
+func (m *UsersResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func (m *UsersResponse) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+type UserPermissionsRequest struct {
+	User                 string       `protobuf:"bytes,1,opt,name=User,proto3" json:"User,omitempty"`
+	Page                 *PageRequest `protobuf:"bytes,2,opt,name=Page,proto3" json:"Page,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *UserPermissionsRequest) Reset()         { *m = UserPermissionsRequest{} }
+func (m *UserPermissionsRequest) String() string { return proto.CompactTextString(m) }
+func (*UserPermissionsRequest) ProtoMessage()    {}
+func (*UserPermissionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{5}
+}
+
+func (m *UserPermissionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UserPermissionsRequest.Unmarshal(m, b)
+}
+func (m *UserPermissionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UserPermissionsRequest.Marshal(b, m, deterministic)
+}
+func (m *UserPermissionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UserPermissionsRequest.Merge(m, src)
+}
+func (m *UserPermissionsRequest) XXX_Size() int {
+	return xxx_messageInfo_UserPermissionsRequest.Size(m)
+}
+func (m *UserPermissionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UserPermissionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UserPermissionsRequest proto.InternalMessageInfo
+
+func (m *UserPermissionsRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *UserPermissionsRequest) GetPage() *PageRequest {
+	if m != nil {
+		return m.Page
+	}
+	return nil
+}
+
 type PermissionsRequest struct {
-	User            string   `protobuf:"bytes,1,opt,name=User" json:"User,omitempty"`
-	PermissionsList []string `protobuf:"bytes,2,rep,name=PermissionsList" json:"PermissionsList,omitempty"`
+	User                 string   `protobuf:"bytes,1,opt,name=User,proto3" json:"User,omitempty"`
+	PermissionsList      []string `protobuf:"bytes,2,rep,name=PermissionsList,proto3" json:"PermissionsList,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PermissionsRequest) Reset()         { *m = PermissionsRequest{} }
+func (m *PermissionsRequest) String() string { return proto.CompactTextString(m) }
+func (*PermissionsRequest) ProtoMessage()    {}
+func (*PermissionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{6}
+}
+
+func (m *PermissionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PermissionsRequest.Unmarshal(m, b)
+}
+func (m *PermissionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PermissionsRequest.Marshal(b, m, deterministic)
+}
+func (m *PermissionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PermissionsRequest.Merge(m, src)
+}
+func (m *PermissionsRequest) XXX_Size() int {
+	return xxx_messageInfo_PermissionsRequest.Size(m)
+}
+func (m *PermissionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PermissionsRequest.DiscardUnknown(m)
 }
 
-func (m *PermissionsRequest) Reset()                    { *m = PermissionsRequest{} }
-func (m *PermissionsRequest) String() string            { return proto.CompactTextString(m) }
-func (*PermissionsRequest) ProtoMessage()               {}
-func (*PermissionsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+var xxx_messageInfo_PermissionsRequest proto.InternalMessageInfo
 
 func (m *PermissionsRequest) GetUser() string {
 	if m != nil {
@@ -99,14 +358,45 @@ func (m *PermissionsRequest) GetPermissionsList() []string {
 }
 
 type Permission struct {
-	Name        string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
-	Description string `protobuf:"bytes,2,opt,name=Description" json:"Description,omitempty"`
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Description          string   `protobuf:"bytes,2,opt,name=Description,proto3" json:"Description,omitempty"`
+	Owner                string   `protobuf:"bytes,3,opt,name=Owner,proto3" json:"Owner,omitempty"`
+	Creator              string   `protobuf:"bytes,4,opt,name=Creator,proto3" json:"Creator,omitempty"`
+	Created              int64    `protobuf:"varint,5,opt,name=Created,proto3" json:"Created,omitempty"`
+	Updated              int64    `protobuf:"varint,6,opt,name=Updated,proto3" json:"Updated,omitempty"`
+	Tags                 []string `protobuf:"bytes,7,rep,name=Tags,proto3" json:"Tags,omitempty"`
+	System               bool     `protobuf:"varint,8,opt,name=System,proto3" json:"System,omitempty"`
+	Revision             int64    `protobuf:"varint,9,opt,name=Revision,proto3" json:"Revision,omitempty"`
+	ExpectedRevision     int64    `protobuf:"varint,10,opt,name=ExpectedRevision,proto3" json:"ExpectedRevision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Permission) Reset()         { *m = Permission{} }
+func (m *Permission) String() string { return proto.CompactTextString(m) }
+func (*Permission) ProtoMessage()    {}
+func (*Permission) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{7}
+}
+
+func (m *Permission) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Permission.Unmarshal(m, b)
+}
+func (m *Permission) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Permission.Marshal(b, m, deterministic)
+}
+func (m *Permission) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Permission.Merge(m, src)
+}
+func (m *Permission) XXX_Size() int {
+	return xxx_messageInfo_Permission.Size(m)
+}
+func (m *Permission) XXX_DiscardUnknown() {
+	xxx_messageInfo_Permission.DiscardUnknown(m)
 }
 
-func (m *Permission) Reset()                    { *m = Permission{} }
-func (m *Permission) String() string            { return proto.CompactTextString(m) }
-func (*Permission) ProtoMessage()               {}
-func (*Permission) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+var xxx_messageInfo_Permission proto.InternalMessageInfo
 
 func (m *Permission) GetName() string {
 	if m != nil {
@@ -122,15 +412,151 @@ func (m *Permission) GetDescription() string {
 	return ""
 }
 
+func (m *Permission) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Permission) GetCreator() string {
+	if m != nil {
+		return m.Creator
+	}
+	return ""
+}
+
+func (m *Permission) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Permission) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
+func (m *Permission) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Permission) GetSystem() bool {
+	if m != nil {
+		return m.System
+	}
+	return false
+}
+
+func (m *Permission) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *Permission) GetExpectedRevision() int64 {
+	if m != nil {
+		return m.ExpectedRevision
+	}
+	return 0
+}
+
+type RenamePermissionRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	NewName              string   `protobuf:"bytes,2,opt,name=NewName,proto3" json:"NewName,omitempty"`
+	ExpectedRevision     int64    `protobuf:"varint,3,opt,name=ExpectedRevision,proto3" json:"ExpectedRevision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RenamePermissionRequest) Reset()         { *m = RenamePermissionRequest{} }
+func (m *RenamePermissionRequest) String() string { return proto.CompactTextString(m) }
+func (*RenamePermissionRequest) ProtoMessage()    {}
+func (*RenamePermissionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{8}
+}
+
+func (m *RenamePermissionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RenamePermissionRequest.Unmarshal(m, b)
+}
+func (m *RenamePermissionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RenamePermissionRequest.Marshal(b, m, deterministic)
+}
+func (m *RenamePermissionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RenamePermissionRequest.Merge(m, src)
+}
+func (m *RenamePermissionRequest) XXX_Size() int {
+	return xxx_messageInfo_RenamePermissionRequest.Size(m)
+}
+func (m *RenamePermissionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RenamePermissionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RenamePermissionRequest proto.InternalMessageInfo
+
+func (m *RenamePermissionRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *RenamePermissionRequest) GetNewName() string {
+	if m != nil {
+		return m.NewName
+	}
+	return ""
+}
+
+func (m *RenamePermissionRequest) GetExpectedRevision() int64 {
+	if m != nil {
+		return m.ExpectedRevision
+	}
+	return 0
+}
+
 type PermissionUser struct {
-	User       string `protobuf:"bytes,1,opt,name=User" json:"User,omitempty"`
-	Permission string `protobuf:"bytes,2,opt,name=Permission" json:"Permission,omitempty"`
+	User                 string      `protobuf:"bytes,1,opt,name=User,proto3" json:"User,omitempty"`
+	Permission           string      `protobuf:"bytes,2,opt,name=Permission,proto3" json:"Permission,omitempty"`
+	ExpectedRevision     int64       `protobuf:"varint,3,opt,name=ExpectedRevision,proto3" json:"ExpectedRevision,omitempty"`
+	Group                *Permission `protobuf:"bytes,4,opt,name=Group,proto3" json:"Group,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *PermissionUser) Reset()         { *m = PermissionUser{} }
+func (m *PermissionUser) String() string { return proto.CompactTextString(m) }
+func (*PermissionUser) ProtoMessage()    {}
+func (*PermissionUser) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{9}
+}
+
+func (m *PermissionUser) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PermissionUser.Unmarshal(m, b)
+}
+func (m *PermissionUser) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PermissionUser.Marshal(b, m, deterministic)
+}
+func (m *PermissionUser) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PermissionUser.Merge(m, src)
+}
+func (m *PermissionUser) XXX_Size() int {
+	return xxx_messageInfo_PermissionUser.Size(m)
+}
+func (m *PermissionUser) XXX_DiscardUnknown() {
+	xxx_messageInfo_PermissionUser.DiscardUnknown(m)
 }
 
-func (m *PermissionUser) Reset()                    { *m = PermissionUser{} }
-func (m *PermissionUser) String() string            { return proto.CompactTextString(m) }
-func (*PermissionUser) ProtoMessage()               {}
-func (*PermissionUser) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+var xxx_messageInfo_PermissionUser proto.InternalMessageInfo
 
 func (m *PermissionUser) GetUser() string {
 	if m != nil {
@@ -146,14 +572,53 @@ func (m *PermissionUser) GetPermission() string {
 	return ""
 }
 
+func (m *PermissionUser) GetExpectedRevision() int64 {
+	if m != nil {
+		return m.ExpectedRevision
+	}
+	return 0
+}
+
+func (m *PermissionUser) GetGroup() *Permission {
+	if m != nil {
+		return m.Group
+	}
+	return nil
+}
+
 type PermissionsResponse struct {
-	PermissionsList []*Permission `protobuf:"bytes,1,rep,name=PermissionsList" json:"PermissionsList,omitempty"`
+	PermissionsList      []*Permission `protobuf:"bytes,1,rep,name=PermissionsList,proto3" json:"PermissionsList,omitempty"`
+	NextPageToken        string        `protobuf:"bytes,2,opt,name=NextPageToken,proto3" json:"NextPageToken,omitempty"`
+	Revision             int64         `protobuf:"varint,3,opt,name=Revision,proto3" json:"Revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *PermissionsResponse) Reset()         { *m = PermissionsResponse{} }
+func (m *PermissionsResponse) String() string { return proto.CompactTextString(m) }
+func (*PermissionsResponse) ProtoMessage()    {}
+func (*PermissionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{10}
 }
 
-func (m *PermissionsResponse) Reset()                    { *m = PermissionsResponse{} }
-func (m *PermissionsResponse) String() string            { return proto.CompactTextString(m) }
-func (*PermissionsResponse) ProtoMessage()               {}
-func (*PermissionsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (m *PermissionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PermissionsResponse.Unmarshal(m, b)
+}
+func (m *PermissionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PermissionsResponse.Marshal(b, m, deterministic)
+}
+func (m *PermissionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PermissionsResponse.Merge(m, src)
+}
+func (m *PermissionsResponse) XXX_Size() int {
+	return xxx_messageInfo_PermissionsResponse.Size(m)
+}
+func (m *PermissionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PermissionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PermissionsResponse proto.InternalMessageInfo
 
 func (m *PermissionsResponse) GetPermissionsList() []*Permission {
 	if m != nil {
@@ -162,14 +627,51 @@ func (m *PermissionsResponse) GetPermissionsList() []*Permission {
 	return nil
 }
 
+func (m *PermissionsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func (m *PermissionsResponse) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
 type PerformResponse struct {
-	CanPerform bool `protobuf:"varint,1,opt,name=CanPerform" json:"CanPerform,omitempty"`
+	CanPerform           bool     `protobuf:"varint,1,opt,name=CanPerform,proto3" json:"CanPerform,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PerformResponse) Reset()         { *m = PerformResponse{} }
+func (m *PerformResponse) String() string { return proto.CompactTextString(m) }
+func (*PerformResponse) ProtoMessage()    {}
+func (*PerformResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{11}
+}
+
+func (m *PerformResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PerformResponse.Unmarshal(m, b)
+}
+func (m *PerformResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PerformResponse.Marshal(b, m, deterministic)
+}
+func (m *PerformResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PerformResponse.Merge(m, src)
+}
+func (m *PerformResponse) XXX_Size() int {
+	return xxx_messageInfo_PerformResponse.Size(m)
+}
+func (m *PerformResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PerformResponse.DiscardUnknown(m)
 }
 
-func (m *PerformResponse) Reset()                    { *m = PerformResponse{} }
-func (m *PerformResponse) String() string            { return proto.CompactTextString(m) }
-func (*PerformResponse) ProtoMessage()               {}
-func (*PerformResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+var xxx_messageInfo_PerformResponse proto.InternalMessageInfo
 
 func (m *PerformResponse) GetCanPerform() bool {
 	if m != nil {
@@ -178,44 +680,1311 @@ func (m *PerformResponse) GetCanPerform() bool {
 	return false
 }
 
-func init() {
-	proto.RegisterType((*NilRequest)(nil), "chremoas.perms.NilRequest")
-	proto.RegisterType((*UsersRequest)(nil), "chremoas.perms.UsersRequest")
-	proto.RegisterType((*UsersResponse)(nil), "chremoas.perms.UsersResponse")
-	proto.RegisterType((*PermissionsRequest)(nil), "chremoas.perms.PermissionsRequest")
-	proto.RegisterType((*Permission)(nil), "chremoas.perms.Permission")
-	proto.RegisterType((*PermissionUser)(nil), "chremoas.perms.PermissionUser")
-	proto.RegisterType((*PermissionsResponse)(nil), "chremoas.perms.PermissionsResponse")
-	proto.RegisterType((*PerformResponse)(nil), "chremoas.perms.PerformResponse")
+type PerformBatchRequest struct {
+	Requests             []*PermissionsRequest `protobuf:"bytes,1,rep,name=Requests,proto3" json:"Requests,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *PerformBatchRequest) Reset()         { *m = PerformBatchRequest{} }
+func (m *PerformBatchRequest) String() string { return proto.CompactTextString(m) }
+func (*PerformBatchRequest) ProtoMessage()    {}
+func (*PerformBatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{12}
+}
+
+func (m *PerformBatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PerformBatchRequest.Unmarshal(m, b)
+}
+func (m *PerformBatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PerformBatchRequest.Marshal(b, m, deterministic)
+}
+func (m *PerformBatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PerformBatchRequest.Merge(m, src)
+}
+func (m *PerformBatchRequest) XXX_Size() int {
+	return xxx_messageInfo_PerformBatchRequest.Size(m)
+}
+func (m *PerformBatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PerformBatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PerformBatchRequest proto.InternalMessageInfo
+
+func (m *PerformBatchRequest) GetRequests() []*PermissionsRequest {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
+type PerformBatchResponse struct {
+	Responses            []*PerformResponse `protobuf:"bytes,1,rep,name=Responses,proto3" json:"Responses,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
 }
 
-func init() { proto.RegisterFile("permissions.proto", fileDescriptor0) }
-
-var fileDescriptor0 = []byte{
-	// 385 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x94, 0x4d, 0x4f, 0xf2, 0x40,
-	0x10, 0xc7, 0x81, 0xe7, 0x51, 0x61, 0x78, 0x93, 0xc1, 0x03, 0xd9, 0x28, 0x92, 0xf5, 0x42, 0x62,
-	0xd2, 0x44, 0xfc, 0x04, 0x2a, 0x17, 0x13, 0x42, 0x48, 0x03, 0x89, 0x89, 0x5e, 0x10, 0xd6, 0xd8,
-	0xc4, 0xb2, 0xb5, 0x53, 0xfd, 0x62, 0x7e, 0x41, 0xb3, 0x4b, 0xd9, 0x6e, 0x29, 0x2f, 0x1e, 0xb8,
-	0x75, 0x67, 0x66, 0x7f, 0xf3, 0x9f, 0xff, 0x64, 0x0b, 0x8d, 0x40, 0x84, 0xbe, 0x47, 0xe4, 0xc9,
-	0x05, 0x39, 0x41, 0x28, 0x23, 0x89, 0xb5, 0xd9, 0x7b, 0x28, 0x7c, 0x39, 0x25, 0x47, 0xe5, 0x88,
-	0x57, 0x00, 0x86, 0xde, 0x87, 0x2b, 0x3e, 0xbf, 0x04, 0x45, 0xdc, 0x81, 0xca, 0x84, 0x44, 0x48,
-	0xf1, 0x19, 0xdb, 0x00, 0x23, 0x83, 0x68, 0xe5, 0x3b, 0xf9, 0x6e, 0xc9, 0xb5, 0x22, 0xfc, 0x1a,
-	0xaa, 0x71, 0x3d, 0x05, 0x72, 0x41, 0x02, 0x19, 0x14, 0x55, 0x60, 0xe0, 0x51, 0xd4, 0xca, 0x77,
-	0xfe, 0x75, 0x4b, 0xae, 0x39, 0x73, 0x17, 0x30, 0xb9, 0x6a, 0x5a, 0x20, 0xfc, 0x57, 0x15, 0x31,
-	0x5c, 0x7f, 0x63, 0x17, 0xea, 0x56, 0xa5, 0x86, 0x15, 0x34, 0x6c, 0x3d, 0xcc, 0xef, 0x6d, 0x81,
-	0x8a, 0x35, 0x9c, 0xfa, 0x62, 0xc5, 0x52, 0xdf, 0xd8, 0x81, 0x72, 0x5f, 0xd0, 0x2c, 0xf4, 0x82,
-	0x48, 0xcd, 0x50, 0xd0, 0x29, 0x3b, 0xc4, 0xfb, 0x50, 0x4b, 0x18, 0xba, 0xff, 0x26, 0x4d, 0x69,
-	0x2b, 0x0a, 0x19, 0x2b, 0x9e, 0xa1, 0x99, 0x9a, 0x2e, 0x36, 0xa4, 0x9f, 0x1d, 0x45, 0xf9, 0x52,
-	0xee, 0x31, 0x27, 0xbd, 0x09, 0x27, 0x29, 0xcb, 0x8e, 0x79, 0xa3, 0x29, 0x6f, 0x32, 0xf4, 0x0d,
-	0xb8, 0x0d, 0xf0, 0x30, 0x5d, 0xc4, 0x51, 0xad, 0xb4, 0xe8, 0x5a, 0x91, 0xde, 0xcf, 0x11, 0x94,
-	0x2d, 0x0c, 0x8e, 0xe0, 0x24, 0x4e, 0x21, 0xdf, 0xde, 0x7a, 0xb5, 0x16, 0x76, 0xb9, 0xa1, 0xc6,
-	0xee, 0xcf, 0x73, 0xf8, 0x08, 0xd5, 0xbb, 0xf9, 0xdc, 0xb2, 0x7f, 0xc7, 0x48, 0x6c, 0x47, 0x8e,
-	0xe7, 0x70, 0x02, 0x8d, 0x14, 0x6a, 0xe9, 0xf8, 0xf6, 0x2b, 0x2a, 0xcf, 0xf6, 0xe4, 0x79, 0x0e,
-	0x07, 0x70, 0xea, 0x0a, 0x5f, 0x7e, 0x8b, 0x83, 0x88, 0x7c, 0x82, 0xb3, 0x75, 0xda, 0x81, 0x74,
-	0x8e, 0xa1, 0xae, 0xd6, 0x6c, 0xaf, 0x2b, 0x23, 0x25, 0x79, 0xa5, 0xec, 0x6a, 0xe7, 0xfe, 0xcc,
-	0x7e, 0xc6, 0xd0, 0x4c, 0x53, 0xf5, 0x53, 0xc5, 0xf3, 0xf5, 0xdb, 0xf6, 0x8b, 0x67, 0x17, 0x5b,
-	0xb2, 0x86, 0xfa, 0xb2, 0xa4, 0xaa, 0xb0, 0xad, 0x77, 0x9f, 0x09, 0x7f, 0xd3, 0xfc, 0x7a, 0xac,
-	0xff, 0x52, 0xb7, 0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0xa4, 0xed, 0x0d, 0x1c, 0xba, 0x04, 0x00,
-	0x00,
+func (m *PerformBatchResponse) Reset()         { *m = PerformBatchResponse{} }
+func (m *PerformBatchResponse) String() string { return proto.CompactTextString(m) }
+func (*PerformBatchResponse) ProtoMessage()    {}
+func (*PerformBatchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{13}
+}
+
+func (m *PerformBatchResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PerformBatchResponse.Unmarshal(m, b)
+}
+func (m *PerformBatchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PerformBatchResponse.Marshal(b, m, deterministic)
+}
+func (m *PerformBatchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PerformBatchResponse.Merge(m, src)
+}
+func (m *PerformBatchResponse) XXX_Size() int {
+	return xxx_messageInfo_PerformBatchResponse.Size(m)
+}
+func (m *PerformBatchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PerformBatchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PerformBatchResponse proto.InternalMessageInfo
+
+func (m *PerformBatchResponse) GetResponses() []*PerformResponse {
+	if m != nil {
+		return m.Responses
+	}
+	return nil
+}
+
+type Identity struct {
+	Platform             string   `protobuf:"bytes,1,opt,name=Platform,proto3" json:"Platform,omitempty"`
+	Id                   string   `protobuf:"bytes,2,opt,name=Id,proto3" json:"Id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Identity) Reset()         { *m = Identity{} }
+func (m *Identity) String() string { return proto.CompactTextString(m) }
+func (*Identity) ProtoMessage()    {}
+func (*Identity) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{14}
+}
+
+func (m *Identity) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Identity.Unmarshal(m, b)
+}
+func (m *Identity) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Identity.Marshal(b, m, deterministic)
+}
+func (m *Identity) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Identity.Merge(m, src)
+}
+func (m *Identity) XXX_Size() int {
+	return xxx_messageInfo_Identity.Size(m)
+}
+func (m *Identity) XXX_DiscardUnknown() {
+	xxx_messageInfo_Identity.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Identity proto.InternalMessageInfo
+
+func (m *Identity) GetPlatform() string {
+	if m != nil {
+		return m.Platform
+	}
+	return ""
+}
+
+func (m *Identity) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type IdentityLink struct {
+	Existing             *Identity `protobuf:"bytes,1,opt,name=Existing,proto3" json:"Existing,omitempty"`
+	Identity             *Identity `protobuf:"bytes,2,opt,name=Identity,proto3" json:"Identity,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *IdentityLink) Reset()         { *m = IdentityLink{} }
+func (m *IdentityLink) String() string { return proto.CompactTextString(m) }
+func (*IdentityLink) ProtoMessage()    {}
+func (*IdentityLink) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{15}
+}
+
+func (m *IdentityLink) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IdentityLink.Unmarshal(m, b)
+}
+func (m *IdentityLink) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IdentityLink.Marshal(b, m, deterministic)
+}
+func (m *IdentityLink) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IdentityLink.Merge(m, src)
+}
+func (m *IdentityLink) XXX_Size() int {
+	return xxx_messageInfo_IdentityLink.Size(m)
+}
+func (m *IdentityLink) XXX_DiscardUnknown() {
+	xxx_messageInfo_IdentityLink.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IdentityLink proto.InternalMessageInfo
+
+func (m *IdentityLink) GetExisting() *Identity {
+	if m != nil {
+		return m.Existing
+	}
+	return nil
+}
+
+func (m *IdentityLink) GetIdentity() *Identity {
+	if m != nil {
+		return m.Identity
+	}
+	return nil
+}
+
+type Principal struct {
+	Id                   string      `protobuf:"bytes,1,opt,name=Id,proto3" json:"Id,omitempty"`
+	Identities           []*Identity `protobuf:"bytes,2,rep,name=Identities,proto3" json:"Identities,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Principal) Reset()         { *m = Principal{} }
+func (m *Principal) String() string { return proto.CompactTextString(m) }
+func (*Principal) ProtoMessage()    {}
+func (*Principal) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{16}
+}
+
+func (m *Principal) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Principal.Unmarshal(m, b)
+}
+func (m *Principal) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Principal.Marshal(b, m, deterministic)
+}
+func (m *Principal) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Principal.Merge(m, src)
+}
+func (m *Principal) XXX_Size() int {
+	return xxx_messageInfo_Principal.Size(m)
+}
+func (m *Principal) XXX_DiscardUnknown() {
+	xxx_messageInfo_Principal.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Principal proto.InternalMessageInfo
+
+func (m *Principal) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Principal) GetIdentities() []*Identity {
+	if m != nil {
+		return m.Identities
+	}
+	return nil
+}
+
+type PermissionsRegistration struct {
+	Service              string        `protobuf:"bytes,1,opt,name=Service,proto3" json:"Service,omitempty"`
+	PermissionsList      []*Permission `protobuf:"bytes,2,rep,name=PermissionsList,proto3" json:"PermissionsList,omitempty"`
+	Command              string        `protobuf:"bytes,3,opt,name=Command,proto3" json:"Command,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *PermissionsRegistration) Reset()         { *m = PermissionsRegistration{} }
+func (m *PermissionsRegistration) String() string { return proto.CompactTextString(m) }
+func (*PermissionsRegistration) ProtoMessage()    {}
+func (*PermissionsRegistration) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{17}
+}
+
+func (m *PermissionsRegistration) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PermissionsRegistration.Unmarshal(m, b)
+}
+func (m *PermissionsRegistration) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PermissionsRegistration.Marshal(b, m, deterministic)
+}
+func (m *PermissionsRegistration) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PermissionsRegistration.Merge(m, src)
+}
+func (m *PermissionsRegistration) XXX_Size() int {
+	return xxx_messageInfo_PermissionsRegistration.Size(m)
+}
+func (m *PermissionsRegistration) XXX_DiscardUnknown() {
+	xxx_messageInfo_PermissionsRegistration.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PermissionsRegistration proto.InternalMessageInfo
+
+func (m *PermissionsRegistration) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *PermissionsRegistration) GetPermissionsList() []*Permission {
+	if m != nil {
+		return m.PermissionsList
+	}
+	return nil
+}
+
+func (m *PermissionsRegistration) GetCommand() string {
+	if m != nil {
+		return m.Command
+	}
+	return ""
+}
+
+type RegistrationResponse struct {
+	Created              []*Permission `protobuf:"bytes,1,rep,name=Created,proto3" json:"Created,omitempty"`
+	Undeclared           []*Permission `protobuf:"bytes,2,rep,name=Undeclared,proto3" json:"Undeclared,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *RegistrationResponse) Reset()         { *m = RegistrationResponse{} }
+func (m *RegistrationResponse) String() string { return proto.CompactTextString(m) }
+func (*RegistrationResponse) ProtoMessage()    {}
+func (*RegistrationResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{18}
+}
+
+func (m *RegistrationResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RegistrationResponse.Unmarshal(m, b)
+}
+func (m *RegistrationResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RegistrationResponse.Marshal(b, m, deterministic)
+}
+func (m *RegistrationResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegistrationResponse.Merge(m, src)
+}
+func (m *RegistrationResponse) XXX_Size() int {
+	return xxx_messageInfo_RegistrationResponse.Size(m)
+}
+func (m *RegistrationResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegistrationResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegistrationResponse proto.InternalMessageInfo
+
+func (m *RegistrationResponse) GetCreated() []*Permission {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *RegistrationResponse) GetUndeclared() []*Permission {
+	if m != nil {
+		return m.Undeclared
+	}
+	return nil
+}
+
+type BulkPermissionUsers struct {
+	Users                []string `protobuf:"bytes,1,rep,name=Users,proto3" json:"Users,omitempty"`
+	Permissions          []string `protobuf:"bytes,2,rep,name=Permissions,proto3" json:"Permissions,omitempty"`
+	Atomic               bool     `protobuf:"varint,3,opt,name=Atomic,proto3" json:"Atomic,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BulkPermissionUsers) Reset()         { *m = BulkPermissionUsers{} }
+func (m *BulkPermissionUsers) String() string { return proto.CompactTextString(m) }
+func (*BulkPermissionUsers) ProtoMessage()    {}
+func (*BulkPermissionUsers) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{19}
+}
+
+func (m *BulkPermissionUsers) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkPermissionUsers.Unmarshal(m, b)
+}
+func (m *BulkPermissionUsers) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkPermissionUsers.Marshal(b, m, deterministic)
+}
+func (m *BulkPermissionUsers) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkPermissionUsers.Merge(m, src)
+}
+func (m *BulkPermissionUsers) XXX_Size() int {
+	return xxx_messageInfo_BulkPermissionUsers.Size(m)
+}
+func (m *BulkPermissionUsers) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkPermissionUsers.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkPermissionUsers proto.InternalMessageInfo
+
+func (m *BulkPermissionUsers) GetUsers() []string {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+func (m *BulkPermissionUsers) GetPermissions() []string {
+	if m != nil {
+		return m.Permissions
+	}
+	return nil
+}
+
+func (m *BulkPermissionUsers) GetAtomic() bool {
+	if m != nil {
+		return m.Atomic
+	}
+	return false
+}
+
+type PermissionUserResult struct {
+	User                 string   `protobuf:"bytes,1,opt,name=User,proto3" json:"User,omitempty"`
+	Permission           string   `protobuf:"bytes,2,opt,name=Permission,proto3" json:"Permission,omitempty"`
+	Success              bool     `protobuf:"varint,3,opt,name=Success,proto3" json:"Success,omitempty"`
+	Error                string   `protobuf:"bytes,4,opt,name=Error,proto3" json:"Error,omitempty"`
+	Reason               string   `protobuf:"bytes,5,opt,name=Reason,proto3" json:"Reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PermissionUserResult) Reset()         { *m = PermissionUserResult{} }
+func (m *PermissionUserResult) String() string { return proto.CompactTextString(m) }
+func (*PermissionUserResult) ProtoMessage()    {}
+func (*PermissionUserResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{20}
+}
+
+func (m *PermissionUserResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PermissionUserResult.Unmarshal(m, b)
+}
+func (m *PermissionUserResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PermissionUserResult.Marshal(b, m, deterministic)
+}
+func (m *PermissionUserResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PermissionUserResult.Merge(m, src)
+}
+func (m *PermissionUserResult) XXX_Size() int {
+	return xxx_messageInfo_PermissionUserResult.Size(m)
+}
+func (m *PermissionUserResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_PermissionUserResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PermissionUserResult proto.InternalMessageInfo
+
+func (m *PermissionUserResult) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *PermissionUserResult) GetPermission() string {
+	if m != nil {
+		return m.Permission
+	}
+	return ""
+}
+
+func (m *PermissionUserResult) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *PermissionUserResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *PermissionUserResult) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type BulkPermissionUsersResponse struct {
+	Results              []*PermissionUserResult `protobuf:"bytes,1,rep,name=Results,proto3" json:"Results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *BulkPermissionUsersResponse) Reset()         { *m = BulkPermissionUsersResponse{} }
+func (m *BulkPermissionUsersResponse) String() string { return proto.CompactTextString(m) }
+func (*BulkPermissionUsersResponse) ProtoMessage()    {}
+func (*BulkPermissionUsersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{21}
+}
+
+func (m *BulkPermissionUsersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkPermissionUsersResponse.Unmarshal(m, b)
+}
+func (m *BulkPermissionUsersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkPermissionUsersResponse.Marshal(b, m, deterministic)
+}
+func (m *BulkPermissionUsersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkPermissionUsersResponse.Merge(m, src)
+}
+func (m *BulkPermissionUsersResponse) XXX_Size() int {
+	return xxx_messageInfo_BulkPermissionUsersResponse.Size(m)
+}
+func (m *BulkPermissionUsersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkPermissionUsersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkPermissionUsersResponse proto.InternalMessageInfo
+
+func (m *BulkPermissionUsersResponse) GetResults() []*PermissionUserResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type SetPermissionUsersRequest struct {
+	Permission           string   `protobuf:"bytes,1,opt,name=Permission,proto3" json:"Permission,omitempty"`
+	Users                []string `protobuf:"bytes,2,rep,name=Users,proto3" json:"Users,omitempty"`
+	DryRun               bool     `protobuf:"varint,3,opt,name=DryRun,proto3" json:"DryRun,omitempty"`
+	ExpectedRevision     int64    `protobuf:"varint,4,opt,name=ExpectedRevision,proto3" json:"ExpectedRevision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetPermissionUsersRequest) Reset()         { *m = SetPermissionUsersRequest{} }
+func (m *SetPermissionUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*SetPermissionUsersRequest) ProtoMessage()    {}
+func (*SetPermissionUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{22}
+}
+
+func (m *SetPermissionUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetPermissionUsersRequest.Unmarshal(m, b)
+}
+func (m *SetPermissionUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetPermissionUsersRequest.Marshal(b, m, deterministic)
+}
+func (m *SetPermissionUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetPermissionUsersRequest.Merge(m, src)
+}
+func (m *SetPermissionUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_SetPermissionUsersRequest.Size(m)
+}
+func (m *SetPermissionUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetPermissionUsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetPermissionUsersRequest proto.InternalMessageInfo
+
+func (m *SetPermissionUsersRequest) GetPermission() string {
+	if m != nil {
+		return m.Permission
+	}
+	return ""
+}
+
+func (m *SetPermissionUsersRequest) GetUsers() []string {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+func (m *SetPermissionUsersRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+func (m *SetPermissionUsersRequest) GetExpectedRevision() int64 {
+	if m != nil {
+		return m.ExpectedRevision
+	}
+	return 0
+}
+
+type SetPermissionUsersResponse struct {
+	Added                []string `protobuf:"bytes,1,rep,name=Added,proto3" json:"Added,omitempty"`
+	Removed              []string `protobuf:"bytes,2,rep,name=Removed,proto3" json:"Removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetPermissionUsersResponse) Reset()         { *m = SetPermissionUsersResponse{} }
+func (m *SetPermissionUsersResponse) String() string { return proto.CompactTextString(m) }
+func (*SetPermissionUsersResponse) ProtoMessage()    {}
+func (*SetPermissionUsersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{23}
+}
+
+func (m *SetPermissionUsersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetPermissionUsersResponse.Unmarshal(m, b)
+}
+func (m *SetPermissionUsersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetPermissionUsersResponse.Marshal(b, m, deterministic)
+}
+func (m *SetPermissionUsersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetPermissionUsersResponse.Merge(m, src)
+}
+func (m *SetPermissionUsersResponse) XXX_Size() int {
+	return xxx_messageInfo_SetPermissionUsersResponse.Size(m)
+}
+func (m *SetPermissionUsersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetPermissionUsersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetPermissionUsersResponse proto.InternalMessageInfo
+
+func (m *SetPermissionUsersResponse) GetAdded() []string {
+	if m != nil {
+		return m.Added
+	}
+	return nil
+}
+
+func (m *SetPermissionUsersResponse) GetRemoved() []string {
+	if m != nil {
+		return m.Removed
+	}
+	return nil
+}
+
+type AuditRequest struct {
+	Limit                int32    `protobuf:"varint,1,opt,name=Limit,proto3" json:"Limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditRequest) Reset()         { *m = AuditRequest{} }
+func (m *AuditRequest) String() string { return proto.CompactTextString(m) }
+func (*AuditRequest) ProtoMessage()    {}
+func (*AuditRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{24}
+}
+
+func (m *AuditRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditRequest.Unmarshal(m, b)
+}
+func (m *AuditRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditRequest.Marshal(b, m, deterministic)
+}
+func (m *AuditRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditRequest.Merge(m, src)
+}
+func (m *AuditRequest) XXX_Size() int {
+	return xxx_messageInfo_AuditRequest.Size(m)
+}
+func (m *AuditRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditRequest proto.InternalMessageInfo
+
+func (m *AuditRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type AuditEntry struct {
+	Time                 int64    `protobuf:"varint,1,opt,name=Time,proto3" json:"Time,omitempty"`
+	Action               string   `protobuf:"bytes,2,opt,name=Action,proto3" json:"Action,omitempty"`
+	Details              []string `protobuf:"bytes,3,rep,name=Details,proto3" json:"Details,omitempty"`
+	Actor                string   `protobuf:"bytes,4,opt,name=Actor,proto3" json:"Actor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditEntry) Reset()         { *m = AuditEntry{} }
+func (m *AuditEntry) String() string { return proto.CompactTextString(m) }
+func (*AuditEntry) ProtoMessage()    {}
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{25}
+}
+
+func (m *AuditEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEntry.Unmarshal(m, b)
+}
+func (m *AuditEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEntry.Marshal(b, m, deterministic)
+}
+func (m *AuditEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEntry.Merge(m, src)
+}
+func (m *AuditEntry) XXX_Size() int {
+	return xxx_messageInfo_AuditEntry.Size(m)
+}
+func (m *AuditEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEntry proto.InternalMessageInfo
+
+func (m *AuditEntry) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *AuditEntry) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *AuditEntry) GetDetails() []string {
+	if m != nil {
+		return m.Details
+	}
+	return nil
+}
+
+func (m *AuditEntry) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+type AuditResponse struct {
+	Entries              []*AuditEntry `protobuf:"bytes,1,rep,name=Entries,proto3" json:"Entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *AuditResponse) Reset()         { *m = AuditResponse{} }
+func (m *AuditResponse) String() string { return proto.CompactTextString(m) }
+func (*AuditResponse) ProtoMessage()    {}
+func (*AuditResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{26}
+}
+
+func (m *AuditResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditResponse.Unmarshal(m, b)
+}
+func (m *AuditResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditResponse.Marshal(b, m, deterministic)
+}
+func (m *AuditResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditResponse.Merge(m, src)
+}
+func (m *AuditResponse) XXX_Size() int {
+	return xxx_messageInfo_AuditResponse.Size(m)
+}
+func (m *AuditResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditResponse proto.InternalMessageInfo
+
+func (m *AuditResponse) GetEntries() []*AuditEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type ExportRequest struct {
+	Format               string   `protobuf:"bytes,1,opt,name=Format,proto3" json:"Format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportRequest) Reset()         { *m = ExportRequest{} }
+func (m *ExportRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()    {}
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{27}
+}
+
+func (m *ExportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportRequest.Unmarshal(m, b)
+}
+func (m *ExportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportRequest.Marshal(b, m, deterministic)
+}
+func (m *ExportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportRequest.Merge(m, src)
+}
+func (m *ExportRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportRequest.Size(m)
+}
+func (m *ExportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportRequest proto.InternalMessageInfo
+
+func (m *ExportRequest) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+type ExportResponse struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+	Format               string   `protobuf:"bytes,2,opt,name=Format,proto3" json:"Format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportResponse) Reset()         { *m = ExportResponse{} }
+func (m *ExportResponse) String() string { return proto.CompactTextString(m) }
+func (*ExportResponse) ProtoMessage()    {}
+func (*ExportResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{28}
+}
+
+func (m *ExportResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportResponse.Unmarshal(m, b)
+}
+func (m *ExportResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportResponse.Marshal(b, m, deterministic)
+}
+func (m *ExportResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportResponse.Merge(m, src)
+}
+func (m *ExportResponse) XXX_Size() int {
+	return xxx_messageInfo_ExportResponse.Size(m)
+}
+func (m *ExportResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportResponse proto.InternalMessageInfo
+
+func (m *ExportResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *ExportResponse) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+type ImportRequest struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+	Format               string   `protobuf:"bytes,2,opt,name=Format,proto3" json:"Format,omitempty"`
+	Mode                 string   `protobuf:"bytes,3,opt,name=Mode,proto3" json:"Mode,omitempty"`
+	DryRun               bool     `protobuf:"varint,4,opt,name=DryRun,proto3" json:"DryRun,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportRequest) Reset()         { *m = ImportRequest{} }
+func (m *ImportRequest) String() string { return proto.CompactTextString(m) }
+func (*ImportRequest) ProtoMessage()    {}
+func (*ImportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{29}
+}
+
+func (m *ImportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportRequest.Unmarshal(m, b)
+}
+func (m *ImportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportRequest.Marshal(b, m, deterministic)
+}
+func (m *ImportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportRequest.Merge(m, src)
+}
+func (m *ImportRequest) XXX_Size() int {
+	return xxx_messageInfo_ImportRequest.Size(m)
+}
+func (m *ImportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportRequest proto.InternalMessageInfo
+
+func (m *ImportRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *ImportRequest) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *ImportRequest) GetMode() string {
+	if m != nil {
+		return m.Mode
+	}
+	return ""
+}
+
+func (m *ImportRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type ImportResponse struct {
+	Changes              []string `protobuf:"bytes,1,rep,name=Changes,proto3" json:"Changes,omitempty"`
+	Revision             int64    `protobuf:"varint,2,opt,name=Revision,proto3" json:"Revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportResponse) Reset()         { *m = ImportResponse{} }
+func (m *ImportResponse) String() string { return proto.CompactTextString(m) }
+func (*ImportResponse) ProtoMessage()    {}
+func (*ImportResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{30}
+}
+
+func (m *ImportResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportResponse.Unmarshal(m, b)
+}
+func (m *ImportResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportResponse.Marshal(b, m, deterministic)
+}
+func (m *ImportResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportResponse.Merge(m, src)
+}
+func (m *ImportResponse) XXX_Size() int {
+	return xxx_messageInfo_ImportResponse.Size(m)
+}
+func (m *ImportResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportResponse proto.InternalMessageInfo
+
+func (m *ImportResponse) GetChanges() []string {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func (m *ImportResponse) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+type CheckRequest struct {
+	Repair               bool     `protobuf:"varint,1,opt,name=Repair,proto3" json:"Repair,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CheckRequest) Reset()         { *m = CheckRequest{} }
+func (m *CheckRequest) String() string { return proto.CompactTextString(m) }
+func (*CheckRequest) ProtoMessage()    {}
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{31}
+}
+
+func (m *CheckRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckRequest.Unmarshal(m, b)
+}
+func (m *CheckRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckRequest.Marshal(b, m, deterministic)
+}
+func (m *CheckRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckRequest.Merge(m, src)
+}
+func (m *CheckRequest) XXX_Size() int {
+	return xxx_messageInfo_CheckRequest.Size(m)
+}
+func (m *CheckRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckRequest proto.InternalMessageInfo
+
+func (m *CheckRequest) GetRepair() bool {
+	if m != nil {
+		return m.Repair
+	}
+	return false
+}
+
+type CheckProblem struct {
+	Kind                 string   `protobuf:"bytes,1,opt,name=Kind,proto3" json:"Kind,omitempty"`
+	Subject              string   `protobuf:"bytes,2,opt,name=Subject,proto3" json:"Subject,omitempty"`
+	Detail               string   `protobuf:"bytes,3,opt,name=Detail,proto3" json:"Detail,omitempty"`
+	Repaired             bool     `protobuf:"varint,4,opt,name=Repaired,proto3" json:"Repaired,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CheckProblem) Reset()         { *m = CheckProblem{} }
+func (m *CheckProblem) String() string { return proto.CompactTextString(m) }
+func (*CheckProblem) ProtoMessage()    {}
+func (*CheckProblem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{32}
+}
+
+func (m *CheckProblem) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckProblem.Unmarshal(m, b)
+}
+func (m *CheckProblem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckProblem.Marshal(b, m, deterministic)
+}
+func (m *CheckProblem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckProblem.Merge(m, src)
+}
+func (m *CheckProblem) XXX_Size() int {
+	return xxx_messageInfo_CheckProblem.Size(m)
+}
+func (m *CheckProblem) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckProblem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckProblem proto.InternalMessageInfo
+
+func (m *CheckProblem) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *CheckProblem) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *CheckProblem) GetDetail() string {
+	if m != nil {
+		return m.Detail
+	}
+	return ""
+}
+
+func (m *CheckProblem) GetRepaired() bool {
+	if m != nil {
+		return m.Repaired
+	}
+	return false
+}
+
+type CheckResponse struct {
+	Problems             []*CheckProblem `protobuf:"bytes,1,rep,name=Problems,proto3" json:"Problems,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *CheckResponse) Reset()         { *m = CheckResponse{} }
+func (m *CheckResponse) String() string { return proto.CompactTextString(m) }
+func (*CheckResponse) ProtoMessage()    {}
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{33}
+}
+
+func (m *CheckResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckResponse.Unmarshal(m, b)
+}
+func (m *CheckResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckResponse.Marshal(b, m, deterministic)
+}
+func (m *CheckResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckResponse.Merge(m, src)
+}
+func (m *CheckResponse) XXX_Size() int {
+	return xxx_messageInfo_CheckResponse.Size(m)
+}
+func (m *CheckResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckResponse proto.InternalMessageInfo
+
+func (m *CheckResponse) GetProblems() []*CheckProblem {
+	if m != nil {
+		return m.Problems
+	}
+	return nil
+}
+
+type HealthResponse struct {
+	Ready                 bool     `protobuf:"varint,1,opt,name=Ready,proto3" json:"Ready,omitempty"`
+	Connected             bool     `protobuf:"varint,2,opt,name=Connected,proto3" json:"Connected,omitempty"`
+	LatencyMicroseconds   int64    `protobuf:"varint,3,opt,name=LatencyMicroseconds,proto3" json:"LatencyMicroseconds,omitempty"`
+	SchemaVersion         int64    `protobuf:"varint,4,opt,name=SchemaVersion,proto3" json:"SchemaVersion,omitempty"`
+	ExpectedSchemaVersion int64    `protobuf:"varint,5,opt,name=ExpectedSchemaVersion,proto3" json:"ExpectedSchemaVersion,omitempty"`
+	AdminsConfigured      bool     `protobuf:"varint,6,opt,name=AdminsConfigured,proto3" json:"AdminsConfigured,omitempty"`
+	Problems              []string `protobuf:"bytes,7,rep,name=Problems,proto3" json:"Problems,omitempty"`
+	XXX_NoUnkeyedLiteral  struct{} `json:"-"`
+	XXX_unrecognized      []byte   `json:"-"`
+	XXX_sizecache         int32    `json:"-"`
+}
+
+func (m *HealthResponse) Reset()         { *m = HealthResponse{} }
+func (m *HealthResponse) String() string { return proto.CompactTextString(m) }
+func (*HealthResponse) ProtoMessage()    {}
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_46cca66312ac1c30, []int{34}
+}
+
+func (m *HealthResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HealthResponse.Unmarshal(m, b)
+}
+func (m *HealthResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HealthResponse.Marshal(b, m, deterministic)
+}
+func (m *HealthResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HealthResponse.Merge(m, src)
+}
+func (m *HealthResponse) XXX_Size() int {
+	return xxx_messageInfo_HealthResponse.Size(m)
+}
+func (m *HealthResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_HealthResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HealthResponse proto.InternalMessageInfo
+
+func (m *HealthResponse) GetReady() bool {
+	if m != nil {
+		return m.Ready
+	}
+	return false
+}
+
+func (m *HealthResponse) GetConnected() bool {
+	if m != nil {
+		return m.Connected
+	}
+	return false
+}
+
+func (m *HealthResponse) GetLatencyMicroseconds() int64 {
+	if m != nil {
+		return m.LatencyMicroseconds
+	}
+	return 0
+}
+
+func (m *HealthResponse) GetSchemaVersion() int64 {
+	if m != nil {
+		return m.SchemaVersion
+	}
+	return 0
+}
+
+func (m *HealthResponse) GetExpectedSchemaVersion() int64 {
+	if m != nil {
+		return m.ExpectedSchemaVersion
+	}
+	return 0
+}
+
+func (m *HealthResponse) GetAdminsConfigured() bool {
+	if m != nil {
+		return m.AdminsConfigured
+	}
+	return false
+}
+
+func (m *HealthResponse) GetProblems() []string {
+	if m != nil {
+		return m.Problems
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*NilRequest)(nil), "chremoas.perms.NilRequest")
+	proto.RegisterType((*PageRequest)(nil), "chremoas.perms.PageRequest")
+	proto.RegisterType((*ListPermissionsRequest)(nil), "chremoas.perms.ListPermissionsRequest")
+	proto.RegisterType((*UsersRequest)(nil), "chremoas.perms.UsersRequest")
+	proto.RegisterType((*UsersResponse)(nil), "chremoas.perms.UsersResponse")
+	proto.RegisterType((*UserPermissionsRequest)(nil), "chremoas.perms.UserPermissionsRequest")
+	proto.RegisterType((*PermissionsRequest)(nil), "chremoas.perms.PermissionsRequest")
+	proto.RegisterType((*Permission)(nil), "chremoas.perms.Permission")
+	proto.RegisterType((*RenamePermissionRequest)(nil), "chremoas.perms.RenamePermissionRequest")
+	proto.RegisterType((*PermissionUser)(nil), "chremoas.perms.PermissionUser")
+	proto.RegisterType((*PermissionsResponse)(nil), "chremoas.perms.PermissionsResponse")
+	proto.RegisterType((*PerformResponse)(nil), "chremoas.perms.PerformResponse")
+	proto.RegisterType((*PerformBatchRequest)(nil), "chremoas.perms.PerformBatchRequest")
+	proto.RegisterType((*PerformBatchResponse)(nil), "chremoas.perms.PerformBatchResponse")
+	proto.RegisterType((*Identity)(nil), "chremoas.perms.Identity")
+	proto.RegisterType((*IdentityLink)(nil), "chremoas.perms.IdentityLink")
+	proto.RegisterType((*Principal)(nil), "chremoas.perms.Principal")
+	proto.RegisterType((*PermissionsRegistration)(nil), "chremoas.perms.PermissionsRegistration")
+	proto.RegisterType((*RegistrationResponse)(nil), "chremoas.perms.RegistrationResponse")
+	proto.RegisterType((*BulkPermissionUsers)(nil), "chremoas.perms.BulkPermissionUsers")
+	proto.RegisterType((*PermissionUserResult)(nil), "chremoas.perms.PermissionUserResult")
+	proto.RegisterType((*BulkPermissionUsersResponse)(nil), "chremoas.perms.BulkPermissionUsersResponse")
+	proto.RegisterType((*SetPermissionUsersRequest)(nil), "chremoas.perms.SetPermissionUsersRequest")
+	proto.RegisterType((*SetPermissionUsersResponse)(nil), "chremoas.perms.SetPermissionUsersResponse")
+	proto.RegisterType((*AuditRequest)(nil), "chremoas.perms.AuditRequest")
+	proto.RegisterType((*AuditEntry)(nil), "chremoas.perms.AuditEntry")
+	proto.RegisterType((*AuditResponse)(nil), "chremoas.perms.AuditResponse")
+	proto.RegisterType((*ExportRequest)(nil), "chremoas.perms.ExportRequest")
+	proto.RegisterType((*ExportResponse)(nil), "chremoas.perms.ExportResponse")
+	proto.RegisterType((*ImportRequest)(nil), "chremoas.perms.ImportRequest")
+	proto.RegisterType((*ImportResponse)(nil), "chremoas.perms.ImportResponse")
+	proto.RegisterType((*CheckRequest)(nil), "chremoas.perms.CheckRequest")
+	proto.RegisterType((*CheckProblem)(nil), "chremoas.perms.CheckProblem")
+	proto.RegisterType((*CheckResponse)(nil), "chremoas.perms.CheckResponse")
+	proto.RegisterType((*HealthResponse)(nil), "chremoas.perms.HealthResponse")
+}
+
+func init() { proto.RegisterFile("permissions.proto", fileDescriptor_46cca66312ac1c30) }
+
+var fileDescriptor_46cca66312ac1c30 = []byte{
+	// 1662 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x18, 0x5b, 0x6f, 0x1b, 0xc5,
+	0x3a, 0x76, 0x6e, 0xf6, 0x17, 0x3b, 0x4d, 0x27, 0x69, 0xea, 0xba, 0x97, 0x13, 0x4d, 0xa3, 0x36,
+	0xa7, 0x47, 0xca, 0x29, 0xa5, 0x42, 0x15, 0x82, 0x4a, 0x69, 0x92, 0x16, 0x0b, 0x37, 0x98, 0x49,
+	0x8c, 0x40, 0x50, 0xa4, 0xed, 0xee, 0xc4, 0x1e, 0xe2, 0xdd, 0x35, 0x3b, 0xeb, 0x36, 0x29, 0x7f,
+	0x80, 0x67, 0x10, 0x2f, 0x48, 0xbc, 0xf0, 0x23, 0xf8, 0x7d, 0x68, 0x66, 0x67, 0x76, 0x67, 0x2f,
+	0x76, 0x9c, 0x12, 0xde, 0xe6, 0x9b, 0xf9, 0xe6, 0xbb, 0xdf, 0x66, 0xe0, 0xea, 0x90, 0x06, 0x2e,
+	0xe3, 0x9c, 0xf9, 0x1e, 0xdf, 0x1e, 0x06, 0x7e, 0xe8, 0xa3, 0x65, 0xbb, 0x1f, 0x50, 0xd7, 0xb7,
+	0xf8, 0xb6, 0x38, 0xe3, 0xb8, 0x06, 0x70, 0xc0, 0x06, 0x84, 0xfe, 0x38, 0xa2, 0x3c, 0xc4, 0x27,
+	0xb0, 0xd4, 0xb1, 0x7a, 0x54, 0x81, 0x08, 0xc1, 0xdc, 0x21, 0x7b, 0x47, 0x1b, 0xa5, 0x8d, 0xd2,
+	0xd6, 0x3c, 0x91, 0x6b, 0xb4, 0x06, 0xf3, 0x47, 0xfe, 0x09, 0xf5, 0x1a, 0xe5, 0x8d, 0xd2, 0x56,
+	0x95, 0x44, 0x00, 0x5a, 0x87, 0x85, 0x4e, 0x40, 0x8f, 0xd9, 0x69, 0x63, 0x56, 0x6e, 0x2b, 0x08,
+	0x35, 0xa1, 0xb2, 0xeb, 0x7b, 0xa1, 0xc5, 0x3c, 0xde, 0x98, 0x93, 0x27, 0x31, 0x8c, 0x5b, 0xb0,
+	0xde, 0x66, 0x3c, 0xec, 0x24, 0x32, 0x6a, 0xbe, 0xff, 0x87, 0x39, 0x21, 0x86, 0xe4, 0xbb, 0xf4,
+	0xe8, 0xe6, 0x76, 0x5a, 0xe6, 0x6d, 0x43, 0x44, 0x22, 0x11, 0xf1, 0x4f, 0x50, 0xeb, 0x72, 0x1a,
+	0xc4, 0x04, 0xee, 0x00, 0x24, 0x64, 0x25, 0x99, 0x2a, 0x31, 0x76, 0x62, 0x06, 0xe5, 0x29, 0x19,
+	0x08, 0x3d, 0x3a, 0x03, 0x2b, 0x3c, 0xf6, 0x03, 0x57, 0x69, 0x18, 0xc3, 0xd8, 0x85, 0xba, 0x62,
+	0xce, 0x87, 0xbe, 0xc7, 0x25, 0xb2, 0xd8, 0x10, 0xca, 0x35, 0x4a, 0x1b, 0xb3, 0x02, 0x59, 0xc3,
+	0x68, 0x13, 0xea, 0x07, 0xf4, 0x34, 0x14, 0x44, 0x4d, 0x33, 0xa6, 0x37, 0x05, 0x05, 0x42, 0xdf,
+	0x30, 0x29, 0xbd, 0x60, 0x37, 0x4b, 0x62, 0x18, 0xbf, 0x82, 0x75, 0x41, 0xad, 0xc0, 0x6c, 0x08,
+	0xe6, 0xc4, 0x89, 0xd2, 0x57, 0xae, 0x2f, 0xac, 0x29, 0x26, 0x80, 0xa6, 0x24, 0xbd, 0x05, 0x57,
+	0x0c, 0x4c, 0xa9, 0x6d, 0x59, 0x6a, 0x9b, 0xdd, 0xc6, 0xbf, 0x97, 0x4d, 0x7f, 0x08, 0x62, 0x07,
+	0x96, 0x4b, 0x35, 0x31, 0xb1, 0x46, 0x1b, 0xb0, 0xb4, 0x47, 0xb9, 0x1d, 0xb0, 0x61, 0x28, 0x94,
+	0x8e, 0xac, 0x62, 0x6e, 0x89, 0xc0, 0xfb, 0xe2, 0xad, 0x47, 0x03, 0x65, 0xff, 0x08, 0x40, 0x0d,
+	0x58, 0xdc, 0x0d, 0xa8, 0x15, 0xfa, 0x81, 0x8a, 0x2f, 0x0d, 0xc6, 0x27, 0xd4, 0x69, 0xcc, 0x4b,
+	0x13, 0x6a, 0x50, 0x9c, 0x74, 0x87, 0x8e, 0x3c, 0x59, 0x88, 0x4e, 0x14, 0x28, 0x24, 0x3b, 0xb2,
+	0x7a, 0xbc, 0xb1, 0x28, 0xf5, 0x90, 0x6b, 0x11, 0xda, 0x87, 0x67, 0x3c, 0xa4, 0x6e, 0xa3, 0xb2,
+	0x51, 0xda, 0xaa, 0x10, 0x05, 0xa5, 0x7c, 0x54, 0x4d, 0xfb, 0x08, 0x3d, 0x80, 0x95, 0xfd, 0xd3,
+	0x21, 0xb5, 0x43, 0xea, 0xc4, 0x38, 0x20, 0x71, 0x72, 0xfb, 0x98, 0xc3, 0x75, 0x42, 0x3d, 0xcb,
+	0xa5, 0x89, 0x85, 0x0c, 0xab, 0xe7, 0x0c, 0xd5, 0x80, 0xc5, 0x03, 0xfa, 0x56, 0x6e, 0x47, 0x46,
+	0xd2, 0x60, 0x21, 0xd3, 0xd9, 0x31, 0x4c, 0xff, 0x2c, 0xc1, 0x72, 0xc2, 0x4f, 0xba, 0xb3, 0xc8,
+	0xc5, 0xe9, 0x3c, 0x2a, 0xe7, 0xf2, 0xe8, 0x02, 0x2c, 0xd1, 0x43, 0x98, 0x7f, 0x11, 0xf8, 0xa3,
+	0xa1, 0xf4, 0xd3, 0xd2, 0xa3, 0x66, 0x2e, 0x14, 0x13, 0xf5, 0x23, 0x44, 0xfc, 0x47, 0x09, 0x56,
+	0x53, 0xb1, 0xa8, 0xf2, 0x6b, 0x2f, 0x1f, 0x78, 0x22, 0xcd, 0x26, 0xd3, 0xcc, 0x5e, 0xb9, 0x84,
+	0x4c, 0xfc, 0x40, 0xca, 0x21, 0x6a, 0x40, 0x2c, 0xda, 0x1d, 0x80, 0x5d, 0xcb, 0x53, 0xbb, 0xd2,
+	0x94, 0x15, 0x62, 0xec, 0xe0, 0x2e, 0xac, 0xaa, 0xe5, 0x33, 0x2b, 0xb4, 0xfb, 0xda, 0xd1, 0x4f,
+	0xa1, 0xa2, 0x96, 0x5c, 0xa9, 0x82, 0xc7, 0xab, 0xa2, 0x93, 0x92, 0xc4, 0x77, 0x70, 0x17, 0xd6,
+	0xd2, 0x64, 0x95, 0x38, 0x9f, 0x42, 0x55, 0xaf, 0x35, 0xe1, 0xff, 0x14, 0x10, 0x36, 0x55, 0x20,
+	0xc9, 0x0d, 0xfc, 0x11, 0x54, 0x5a, 0x0e, 0xf5, 0x42, 0x16, 0x9e, 0xa5, 0x2a, 0x60, 0x29, 0x5d,
+	0x01, 0xd1, 0x32, 0x94, 0x5b, 0x8e, 0xb2, 0x5f, 0xb9, 0xe5, 0xe0, 0x77, 0x50, 0xd3, 0xf7, 0xda,
+	0xcc, 0x3b, 0x41, 0x8f, 0xa1, 0xb2, 0x7f, 0xca, 0x78, 0xc8, 0xbc, 0x9e, 0xaa, 0xe9, 0x8d, 0xac,
+	0x14, 0x1a, 0x9f, 0xc4, 0x98, 0xe2, 0x96, 0xde, 0x6d, 0x94, 0xcf, 0xbb, 0xa5, 0x57, 0xb8, 0x0b,
+	0xd5, 0x4e, 0xc0, 0x3c, 0x9b, 0x0d, 0xad, 0x81, 0x12, 0xac, 0xa4, 0x05, 0x43, 0x4f, 0x00, 0x14,
+	0x22, 0xa3, 0x5c, 0x56, 0xab, 0x49, 0x44, 0x0d, 0x5c, 0xfc, 0x6b, 0x09, 0xae, 0xa7, 0x5c, 0xd0,
+	0x63, 0x3c, 0x0c, 0x2c, 0x59, 0x99, 0x1a, 0xb0, 0x78, 0x48, 0x83, 0x37, 0xcc, 0xd6, 0x99, 0xaa,
+	0xc1, 0xa2, 0x48, 0x2d, 0x5f, 0x3c, 0x52, 0x45, 0x25, 0xf3, 0x5d, 0xd7, 0xf2, 0x1c, 0x55, 0xfb,
+	0x34, 0x88, 0x7f, 0x2e, 0xc1, 0x9a, 0x29, 0x4a, 0xec, 0xf8, 0xc7, 0x49, 0xf1, 0x3b, 0x3f, 0x35,
+	0x34, 0x2a, 0xfa, 0x18, 0xa0, 0xeb, 0x39, 0xd4, 0x1e, 0x58, 0x01, 0x75, 0xa6, 0x90, 0xd4, 0xc0,
+	0xc6, 0x14, 0x56, 0x9f, 0x8d, 0x06, 0x27, 0xe9, 0xa2, 0xc2, 0x45, 0xd5, 0x96, 0x0b, 0xd5, 0x08,
+	0x23, 0x40, 0x54, 0xfb, 0x04, 0x91, 0xab, 0xb6, 0x61, 0x6e, 0x89, 0xaa, 0xbb, 0x13, 0xfa, 0x2e,
+	0xb3, 0xa5, 0xca, 0x15, 0xa2, 0x20, 0xfc, 0x4b, 0x09, 0xd6, 0x12, 0x3c, 0x41, 0x8d, 0x50, 0x3e,
+	0x1a, 0x84, 0xef, 0x55, 0xbe, 0x84, 0xe3, 0x46, 0xb6, 0x4d, 0x39, 0x57, 0x5c, 0x34, 0x28, 0xc4,
+	0xde, 0x0f, 0x82, 0xb8, 0xa9, 0x44, 0x80, 0x10, 0x8a, 0x50, 0x8b, 0xfb, 0x9e, 0xec, 0x28, 0x55,
+	0xa2, 0x20, 0xfc, 0x0a, 0x6e, 0x16, 0xe8, 0x1e, 0x3b, 0xe3, 0x29, 0x2c, 0x46, 0x42, 0xea, 0x1c,
+	0xdc, 0x1c, 0x6f, 0xd3, 0x44, 0x23, 0xa2, 0x2f, 0xe1, 0xdf, 0x4a, 0x70, 0xe3, 0x90, 0x86, 0x39,
+	0xf2, 0xd3, 0xcd, 0x3a, 0xb1, 0x07, 0xca, 0xa6, 0x07, 0xd6, 0x61, 0x61, 0x2f, 0x38, 0x23, 0x23,
+	0x4f, 0xdb, 0x37, 0x82, 0x0a, 0x2b, 0xfa, 0xdc, 0x98, 0x26, 0xd2, 0x86, 0x66, 0x91, 0x58, 0x4a,
+	0xeb, 0x35, 0x98, 0xdf, 0x71, 0x1c, 0x15, 0x80, 0x55, 0x12, 0x01, 0xc2, 0xe4, 0x84, 0xba, 0xfe,
+	0x1b, 0x15, 0x5f, 0x55, 0xa2, 0x41, 0xbc, 0x09, 0xb5, 0x9d, 0x91, 0xc3, 0x42, 0xad, 0xd7, 0x1a,
+	0xcc, 0xb7, 0x99, 0xcb, 0x42, 0x35, 0x7d, 0x46, 0x00, 0xee, 0x03, 0x48, 0xac, 0x7d, 0x2f, 0x0c,
+	0xce, 0x64, 0xbf, 0x66, 0xaa, 0x41, 0xce, 0x12, 0xb9, 0x96, 0x91, 0x63, 0x1b, 0x43, 0x84, 0x82,
+	0x04, 0xe7, 0x3d, 0x1a, 0x5a, 0x6c, 0x20, 0x9c, 0x2d, 0x39, 0x2b, 0x50, 0x4a, 0x6a, 0x27, 0x13,
+	0x44, 0x04, 0xe0, 0x7d, 0xa8, 0x2b, 0x79, 0x92, 0x9c, 0x12, 0x5c, 0x59, 0x5c, 0x4a, 0x73, 0xa9,
+	0x91, 0x48, 0x46, 0x34, 0x2a, 0xbe, 0x0f, 0xf5, 0xfd, 0xd3, 0xa1, 0x1f, 0xc4, 0x7a, 0xad, 0xc3,
+	0xc2, 0x73, 0x3f, 0x70, 0xad, 0x50, 0xf9, 0x4a, 0x41, 0xf8, 0x13, 0x58, 0xd6, 0x88, 0x8a, 0x21,
+	0x82, 0xb9, 0x3d, 0x2b, 0xb4, 0x24, 0x5e, 0x8d, 0xc8, 0xb5, 0x71, 0xbb, 0x9c, 0xba, 0xdd, 0x83,
+	0x7a, 0xcb, 0x35, 0xd9, 0x5c, 0xe0, 0xb2, 0xc0, 0x7d, 0xe9, 0x3b, 0x54, 0x55, 0x17, 0xb9, 0x36,
+	0x02, 0x64, 0xce, 0x0c, 0x10, 0xfc, 0x1c, 0x96, 0x5b, 0x6e, 0x4a, 0x4c, 0x51, 0x9e, 0xfa, 0x96,
+	0xd7, 0xa3, 0x3a, 0xc9, 0x35, 0x98, 0x6a, 0x9e, 0xe5, 0x4c, 0xf3, 0xbc, 0x07, 0xb5, 0xdd, 0x3e,
+	0xb5, 0x4f, 0x0c, 0xb3, 0x10, 0x3a, 0xb4, 0x58, 0xa0, 0xba, 0xa6, 0x82, 0xf0, 0x50, 0xe1, 0x75,
+	0x02, 0xff, 0xf5, 0x80, 0xba, 0x42, 0xd6, 0xcf, 0x99, 0xa7, 0x8b, 0xba, 0x5c, 0x47, 0x79, 0xfc,
+	0xfa, 0x07, 0x6a, 0x6b, 0xc5, 0x34, 0x28, 0xb5, 0x90, 0x5e, 0xd6, 0xef, 0x92, 0x08, 0x8a, 0x24,
+	0x13, 0xf4, 0xa9, 0xa3, 0xf4, 0x8b, 0x61, 0xdc, 0x82, 0xba, 0x92, 0x4c, 0x29, 0xf8, 0x04, 0x2a,
+	0x8a, 0xbb, 0xf6, 0xfc, 0xad, 0xac, 0xe7, 0x4d, 0x11, 0x49, 0x8c, 0x2d, 0x06, 0xdf, 0xe5, 0xcf,
+	0xa8, 0x35, 0x08, 0xfb, 0x66, 0x5a, 0x10, 0x6a, 0x39, 0x67, 0x4a, 0xcd, 0x08, 0x40, 0xb7, 0xa0,
+	0xba, 0xeb, 0x7b, 0x9e, 0xcc, 0x2f, 0xa9, 0x43, 0x85, 0x24, 0x1b, 0xe8, 0x21, 0xac, 0xb6, 0xad,
+	0x90, 0x7a, 0xf6, 0xd9, 0x4b, 0x66, 0x07, 0x3e, 0xa7, 0xb6, 0xef, 0x39, 0x5c, 0xcd, 0x23, 0x45,
+	0x47, 0x62, 0xb8, 0x39, 0xb4, 0xfb, 0xd4, 0xb5, 0xbe, 0xa2, 0x81, 0x91, 0xc3, 0xe9, 0x4d, 0xf4,
+	0x18, 0xae, 0xe9, 0xa4, 0x4e, 0x63, 0x47, 0x03, 0x73, 0xf1, 0xa1, 0x28, 0x11, 0x3b, 0x8e, 0xcb,
+	0x3c, 0xbe, 0xeb, 0x7b, 0xc7, 0xac, 0x37, 0x0a, 0xd4, 0x1c, 0x5d, 0x21, 0xb9, 0x7d, 0x39, 0x35,
+	0x68, 0xd3, 0x45, 0x43, 0x75, 0x0c, 0x3f, 0xfa, 0x0b, 0xa5, 0xba, 0x00, 0xea, 0xc0, 0xa2, 0x9a,
+	0x45, 0xd0, 0x14, 0xd3, 0x4f, 0xf3, 0xbc, 0x41, 0x06, 0xcf, 0xa0, 0x6f, 0xa1, 0x66, 0x8e, 0x45,
+	0xe8, 0xee, 0x98, 0x2b, 0xe6, 0x2c, 0xd6, 0xdc, 0x9c, 0x8c, 0x14, 0x13, 0x6f, 0x41, 0x7d, 0xc7,
+	0x71, 0x8c, 0x42, 0x3b, 0xa1, 0x53, 0x36, 0x27, 0x9c, 0xe1, 0x19, 0xd4, 0x85, 0xab, 0x29, 0x52,
+	0x51, 0xf3, 0x9a, 0xdc, 0x24, 0x9a, 0xe7, 0x9c, 0xe3, 0x19, 0xd4, 0x86, 0x95, 0xe8, 0x61, 0x73,
+	0x29, 0x42, 0x7e, 0x03, 0x2b, 0xd9, 0x77, 0x0a, 0xba, 0x9f, 0xbd, 0x31, 0xe6, 0x25, 0x73, 0x0e,
+	0xe9, 0x36, 0xac, 0x44, 0x5d, 0xe0, 0x52, 0x04, 0xfd, 0x1a, 0xd6, 0xb2, 0xd4, 0x2e, 0xc9, 0xa0,
+	0x7d, 0x40, 0x39, 0x3f, 0xf1, 0x7c, 0x54, 0x15, 0xcc, 0x02, 0xcd, 0xff, 0x4d, 0x81, 0x64, 0x04,
+	0xd7, 0x09, 0x5c, 0x2b, 0xd2, 0xe1, 0xdf, 0x61, 0xe6, 0x02, 0xca, 0xf7, 0x71, 0xf4, 0xdf, 0x2c,
+	0x91, 0xb1, 0x23, 0x48, 0xf3, 0xc1, 0x34, 0xa8, 0x31, 0xbb, 0x23, 0xb8, 0x92, 0xf9, 0xf7, 0xc9,
+	0x3b, 0x3b, 0xf9, 0x93, 0x6a, 0xde, 0x9d, 0x58, 0x0b, 0x62, 0xaa, 0x0e, 0xac, 0x66, 0xa8, 0xca,
+	0x8f, 0x9b, 0x7b, 0xd9, 0xdb, 0xc5, 0x5f, 0x4e, 0xd3, 0x72, 0x69, 0x41, 0xfd, 0x85, 0xa9, 0xdb,
+	0x3f, 0x08, 0xd3, 0xa3, 0xac, 0xc0, 0x91, 0xd9, 0x73, 0xad, 0x25, 0x65, 0xe9, 0xdb, 0x63, 0x4e,
+	0x63, 0x01, 0xbf, 0x8b, 0xa8, 0x66, 0x7e, 0x88, 0xce, 0x8d, 0xfd, 0x29, 0xd5, 0xef, 0xc3, 0xf5,
+	0x02, 0xea, 0xc5, 0x86, 0x2e, 0xfe, 0xa4, 0x9a, 0xde, 0xd0, 0x35, 0xf1, 0x74, 0x8c, 0x9f, 0x9f,
+	0xb7, 0xc6, 0xbd, 0xd2, 0x04, 0x56, 0xf3, 0x46, 0x8e, 0xa8, 0x7e, 0x02, 0xe2, 0x19, 0xf4, 0x02,
+	0x96, 0xbb, 0xde, 0xc0, 0x24, 0x36, 0xf6, 0xc9, 0x37, 0x99, 0xd0, 0x3e, 0xd4, 0x84, 0xf3, 0xf5,
+	0xce, 0xfb, 0x92, 0x39, 0x86, 0xd5, 0xe8, 0xcd, 0x96, 0x76, 0xd1, 0xfd, 0x89, 0x86, 0x49, 0xde,
+	0x78, 0xf9, 0x06, 0x55, 0xf4, 0x02, 0xc4, 0x33, 0xe8, 0x7b, 0xb8, 0x21, 0x9d, 0x15, 0xbf, 0xd1,
+	0x2e, 0x39, 0xe3, 0xbe, 0x84, 0x15, 0x41, 0x3f, 0x1e, 0x7a, 0x19, 0x2d, 0x88, 0x5e, 0x73, 0xa4,
+	0x6f, 0xde, 0x1e, 0x73, 0x6a, 0x78, 0x7d, 0x21, 0x9a, 0x81, 0x51, 0x0e, 0x35, 0x35, 0x44, 0x37,
+	0xef, 0x8c, 0x3b, 0x36, 0x49, 0xb5, 0xdc, 0x62, 0x52, 0x2d, 0x77, 0x22, 0xa9, 0xf4, 0x78, 0x8b,
+	0x67, 0xd0, 0x73, 0x98, 0x97, 0xf3, 0x1d, 0x2a, 0x1e, 0xfb, 0xc6, 0x6a, 0x97, 0x9a, 0x22, 0x25,
+	0x9d, 0x85, 0x68, 0x18, 0x9c, 0x68, 0xfd, 0x9c, 0x3c, 0xe9, 0x01, 0x12, 0xcf, 0xbc, 0x5e, 0x90,
+	0x5f, 0xf9, 0x1f, 0xfe, 0x3d, 0x00, 0x3f, 0x04, 0x29, 0x2f, 0xdf, 0x17, 0x00, 0x00,
 }
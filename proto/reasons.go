@@ -0,0 +1,29 @@
+package chremoas_perms
+
+// Reasons perms-srv gives when it refuses a request. They are the Id of the
+// go-micro errors it returns, so callers can tell failures apart without
+// matching on the message. Don't change them, clients depend on them.
+const (
+	ReasonInvalidRequest    = "perms.invalid_request"
+	ReasonInvalidName       = "perms.invalid_name"
+	ReasonInvalidUser       = "perms.invalid_user"
+	ReasonGroupNotFound     = "perms.group_not_found"
+	ReasonGroupExists       = "perms.group_exists"
+	ReasonGroupNotEmpty     = "perms.group_not_empty"
+	ReasonProtectedGroup    = "perms.protected_group"
+	ReasonAlreadyMember     = "perms.already_member"
+	ReasonNotMember         = "perms.not_member"
+	ReasonIdentityLinked    = "perms.identity_linked"
+	ReasonIdentityNotLinked = "perms.identity_not_linked"
+	ReasonChanged           = "perms.changed"
+	ReasonRevisionMismatch  = "perms.revision_mismatch"
+
+	// Bulk changes report it for items an atomic request didn't apply
+	// because another item failed.
+	ReasonNotApplied = "perms.not_applied"
+
+	// Only the HTTP API refuses callers: those without the token, and users
+	// not allowed to make changes.
+	ReasonUnauthorized = "perms.unauthorized"
+	ReasonNotAllowed   = "perms.not_allowed"
+)
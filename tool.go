@@ -0,0 +1,260 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/chremoas/services-common/config"
+	redis "github.com/chremoas/services-common/redis"
+	goredis "github.com/go-redis/redis"
+
+	"github.com/chremoas/perms-srv/handler"
+	permsrv "github.com/chremoas/perms-srv/proto"
+)
+
+// Admin commands that work on the database directly, without the rest of
+// the service running:
+//
+//	perms-srv export [-format json|yaml] [-o file]
+//	perms-srv import [-format json|yaml] [-mode merge|replace] [-dry-run] [-admins] [file]
+//	perms-srv migrate [-dry-run] [-drop-orphans] [-target-addr host:port] [-target-prefix prefix]
+//	perms-srv check [-repair]
+//	perms-srv ui-key [-revoke] user
+//
+// Each sets up its flags and returns what to run once they are parsed and the
+// configuration is loaded. They talk to Redis directly rather than through a
+// handler, which would migrate the schema and start serving metrics.
+var tools = map[string]func(flags *flag.FlagSet) func(conf *config.Configuration) error{
+	"export":  exportTool,
+	"import":  importTool,
+	"migrate": migrateTool,
+	"check":   checkTool,
+	"ui-key":  uiKeyTool,
+}
+
+// isTool reports whether the command line asks for an admin command rather
+// than the service.
+func isTool(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+
+	_, ok := tools[args[1]]
+	return ok
+}
+
+// runTool runs the named admin command and returns the exit status.
+func runTool(name string, args []string) int {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	confFile := flags.String("configuration_file", configurationFile(), "The yaml configuration file for the service")
+	run := tools[name](flags)
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	conf := config.Configuration{}
+	if err := conf.Load(*confFile); err != nil || !conf.IsInitialized() {
+		fmt.Fprintf(os.Stderr, "Couldn't load the configuration from %s: %v\n", *confFile, err)
+		return 1
+	}
+
+	var err error
+	if logger, err = handler.NewLogger(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer logger.Sync()
+
+	if err = run(&conf); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func configurationFile() string {
+	if file, ok := os.LookupEnv("CONFIGURATION_FILE"); ok {
+		return file
+	}
+
+	return "/etc/auth-srv/application.yaml"
+}
+
+func exportTool(flags *flag.FlagSet) func(conf *config.Configuration) error {
+	format := flags.String("format", permsrv.DumpJSON, "Dump format, json or yaml")
+	out := flags.String("o", "", "Write the dump to this file instead of stdout")
+
+	return func(conf *config.Configuration) error {
+		response, err := handler.ExportDump(redis.Init(conf.LookupService("srv", "perms")), &permsrv.ExportRequest{Format: *format})
+		if err != nil {
+			return err
+		}
+
+		if *out == "" {
+			_, err = os.Stdout.Write(response.Data)
+			return err
+		}
+
+		return ioutil.WriteFile(*out, response.Data, 0600)
+	}
+}
+
+func importTool(flags *flag.FlagSet) func(conf *config.Configuration) error {
+	format := flags.String("format", permsrv.DumpJSON, "Dump format, json or yaml")
+	mode := flags.String("mode", permsrv.ImportMerge, "merge adds and updates, replace also removes what isn't in the dump")
+	dryRun := flags.Bool("dry-run", false, "Only print the changes")
+	admins := flags.Bool("admins", false, "Also change the server_admins group and its members' identities")
+
+	return func(conf *config.Configuration) error {
+		var data []byte
+		var err error
+		if in := flags.Arg(0); in == "" || in == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(in)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		request := &permsrv.ImportRequest{Data: data, Format: *format, Mode: *mode, DryRun: *dryRun}
+		response, err := handler.ImportDump(redis.Init(conf.LookupService("srv", "perms")), request, *admins)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range response.Changes {
+			fmt.Println(change)
+		}
+
+		switch {
+		case len(response.Changes) == 0:
+			fmt.Println("Nothing to change.")
+		case *dryRun:
+			fmt.Printf("%d changes, none applied (dry run).\n", len(response.Changes))
+		default:
+			fmt.Printf("%d changes applied, now at revision %d.\n", len(response.Changes), response.Revision)
+		}
+
+		return nil
+	}
+}
+
+func migrateTool(flags *flag.FlagSet) func(conf *config.Configuration) error {
+	dryRun := flags.Bool("dry-run", false, "Only report what would change")
+	dropOrphans := flags.Bool("drop-orphans", false, "Remove members sets of groups that don't exist, in place only")
+	targetAddr := flags.String("target-addr", "", "Redis to migrate to, instead of in place")
+	targetPassword := flags.String("target-password", "", "Password of the target Redis")
+	targetPrefix := flags.String("target-prefix", "", "Key prefix to migrate to, the configured one by default")
+
+	return func(conf *config.Configuration) error {
+		// Not a handler, that would start changing things before we looked.
+		source := redis.Init(conf.LookupService("srv", "perms"))
+		options := handler.MigrateOptions{DryRun: *dryRun, DropOrphans: *dropOrphans}
+
+		if *targetAddr != "" || *targetPrefix != "" {
+			options.Target = &redis.Client{Client: source.Client, Prefix: source.Prefix}
+			if *targetAddr != "" {
+				options.Target.Client = goredis.NewClient(&goredis.Options{Addr: *targetAddr, Password: *targetPassword})
+			}
+
+			if *targetPrefix != "" {
+				options.Target.Prefix = *targetPrefix
+			}
+		}
+
+		report, err := handler.Migrate(source, options)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%d groups with %d members.\n", report.Groups, report.Members)
+		printList("Orphaned members sets", report.Orphans)
+		printList("Members stored as Discord mentions", report.Mentions)
+		printList("Members stored as bare ids", report.BareIds)
+		printList("Can't migrate", report.Invalid)
+		printList("Changes", report.Changes)
+		printList("Verification failed", report.Problems)
+
+		if len(report.Problems) > 0 {
+			return fmt.Errorf("The migrated data doesn't match, see above.")
+		}
+
+		return nil
+	}
+}
+
+func checkTool(flags *flag.FlagSet) func(conf *config.Configuration) error {
+	repair := flags.Bool("repair", false, "Repair what can be repaired")
+
+	return func(conf *config.Configuration) error {
+		response, err := handler.CheckStore(redis.Init(conf.LookupService("srv", "perms")), &permsrv.CheckRequest{Repair: *repair})
+		if err != nil {
+			return err
+		}
+
+		left := 0
+		for _, problem := range response.Problems {
+			status := "repaired"
+			if !problem.Repaired {
+				status, left = "found", left+1
+			}
+
+			fmt.Printf("%s %s %s: %s\n", status, problem.Kind, problem.Subject, problem.Detail)
+		}
+
+		if left > 0 {
+			return fmt.Errorf("%d problems left.", left)
+		}
+
+		fmt.Println("No problems left.")
+		return nil
+	}
+}
+
+func printList(title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+
+	fmt.Printf("%s (%d):\n", title, len(items))
+	for _, item := range items {
+		fmt.Printf("  %s\n", item)
+	}
+}
+
+func uiKeyTool(flags *flag.FlagSet) func(conf *config.Configuration) error {
+	revoke := flags.Bool("revoke", false, "Revoke the user's key instead of issuing one")
+
+	return func(conf *config.Configuration) error {
+		user := flags.Arg(0)
+		if user == "" {
+			return fmt.Errorf("Name the user whose UI key it is.")
+		}
+
+		client := redis.Init(conf.LookupService("srv", "perms"))
+		if *revoke {
+			if err := handler.RevokeUIKey(client, user); err != nil {
+				return err
+			}
+
+			fmt.Printf("Revoked the UI key of %s and ended their sessions.\n", user)
+			return nil
+		}
+
+		key, err := handler.IssueUIKey(client, user)
+		if err != nil {
+			return err
+		}
+
+		// Only the key goes to stdout, so it can be piped on.
+		fmt.Println(key)
+		fmt.Fprintf(os.Stderr, "Issued a UI key to %s, replacing the one they had. It can't be shown again.\n", user)
+		return nil
+	}
+}